@@ -1,5 +1,8 @@
 module gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git
 
-require gitlab.kaaiot.net/core/lib/iamcore/irn.git v0.0.0-20240524070936-4dcc543e8828
+require (
+	gitlab.kaaiot.net/core/lib/iamcore/irn.git v0.0.0-20240524070936-4dcc543e8828
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 go 1.16
@@ -0,0 +1,152 @@
+package iamcore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+var ErrInvalidAnonymousPolicy = errors.New("invalid anonymous policy")
+
+const anonymousAuthenticatorName = "anonymous"
+
+// AnonymousMode defines how WithAuth treats requests without credentials.
+type AnonymousMode int
+
+const (
+	// AnonymousResolve resolves the anonymous principal on iamcore; the default behavior.
+	AnonymousResolve AnonymousMode = iota
+	// AnonymousReject rejects anonymous requests with 401 Unauthorized without calling iamcore.
+	AnonymousReject
+	// AnonymousStatic accepts anonymous requests as the configured anonymous principal without calling iamcore.
+	AnonymousStatic
+)
+
+// AnonymousPolicy defines how WithAuth treats requests that carry no credentials recognized by any of the authenticators.
+type AnonymousPolicy struct {
+	// Mode of anonymous access.
+	Mode AnonymousMode
+	// PrincipalIRN is the anonymous principal IRN; required for AnonymousStatic mode.
+	PrincipalIRN *irn.IRN
+	// Methods restricts anonymous access to the listed HTTP methods; any method if empty.
+	Methods []string
+	// PathPatterns restricts anonymous access to the request paths matching any of the patterns; any path if empty.
+	// Patterns have path.Match syntax; the pattern ending with "/**" matches the prefix, which may contain
+	// path.Match syntax too, and all the nested paths. The request path is cleaned before matching.
+	PathPatterns []string
+}
+
+// WithAnonymousPolicy configures how WithAuth treats requests without credentials. The configured policy replaces
+// EmptyHeader authenticator in the authenticator chain.
+func WithAnonymousPolicy(policy AnonymousPolicy) Option {
+	return func(o *Options) {
+		o.anonymousPolicy = &policy
+	}
+}
+
+func (p *AnonymousPolicy) validate() error {
+	if p.Mode == AnonymousStatic && p.PrincipalIRN == nil {
+		return fmt.Errorf("anonymous principal IRN is required for static anonymous mode: %w", ErrInvalidAnonymousPolicy)
+	}
+
+	for _, pattern := range p.PathPatterns {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), "/"); err != nil {
+			return fmt.Errorf("path pattern %q: %v: %w", pattern, err, ErrInvalidAnonymousPolicy)
+		}
+	}
+
+	return nil
+}
+
+func (p *AnonymousPolicy) authenticate(r *http.Request, iamcore *ServerClient) (*Principal, error) {
+	if p.Mode == AnonymousReject || !p.allows(r) {
+		return nil, fmt.Errorf("anonymous access is not allowed: %w", ErrNoCredentials)
+	}
+
+	if p.Mode == AnonymousStatic {
+		return &Principal{
+			IRN:           p.PrincipalIRN,
+			Kind:          PrincipalKindAnonymous,
+			Authenticator: anonymousAuthenticatorName,
+		}, nil
+	}
+
+	principal, _, err := NewEmptyHeader(iamcore).Authenticate(r.Context(), nil)
+
+	return principal, err
+}
+
+func (p *AnonymousPolicy) allows(r *http.Request) bool {
+	return p.allowsMethod(r.Method) && p.allowsPath(r.URL.Path)
+}
+
+func (p *AnonymousPolicy) allowsMethod(method string) bool {
+	if len(p.Methods) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.Methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsPath matches the cleaned request path, so that dot segments cannot escape the allowed prefix,
+// e.g. "/public/../admin" does not match "/public/**".
+func (p *AnonymousPolicy) allowsPath(requestPath string) bool {
+	if len(p.PathPatterns) == 0 {
+		return true
+	}
+
+	requestPath = path.Clean("/" + requestPath)
+
+	for _, pattern := range p.PathPatterns {
+		if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+			if matchesPathPrefix(prefix, requestPath) {
+				return true
+			}
+
+			continue
+		}
+
+		if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPathPrefix reports whether the leading segments of the path match the prefix pattern.
+func matchesPathPrefix(prefix, requestPath string) bool {
+	prefixSegments := strings.Split(prefix, "/")
+	pathSegments := strings.Split(requestPath, "/")
+
+	if len(pathSegments) < len(prefixSegments) {
+		return false
+	}
+
+	matched, _ := path.Match(prefix, strings.Join(pathSegments[:len(prefixSegments)], "/"))
+
+	return matched
+}
+
+// withoutEmptyHeader drops EmptyHeader authenticators from the chain.
+func withoutEmptyHeader(authenticators []Authenticator) []Authenticator {
+	filtered := make([]Authenticator, 0, len(authenticators))
+
+	for _, authenticator := range authenticators {
+		if _, ok := authenticator.(*EmptyHeader); !ok {
+			filtered = append(filtered, authenticator)
+		}
+	}
+
+	return filtered
+}
@@ -3,11 +3,13 @@ package iamcore
 import (
 	"context"
 	"net/http"
-
-	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
-const apiKeyHeaderName = "X-iamcore-API-Key" //#nosec
+const (
+	apiKeyHeaderName = "X-iamcore-API-Key" //#nosec
+
+	apiKeyAuthenticatorName = "api-key"
+)
 
 type APIKey struct {
 	iamcore *ServerClient
@@ -19,7 +21,7 @@ func NewAPIKey(iamcore *ServerClient) *APIKey {
 	}
 }
 
-func (a *APIKey) Authenticate(ctx context.Context, header http.Header) (*irn.IRN, http.Header, error) {
+func (a *APIKey) Authenticate(ctx context.Context, header http.Header) (*Principal, http.Header, error) {
 	apiKeyHeader := header.Get(apiKeyHeaderName)
 	if len(apiKeyHeader) == 0 {
 		return nil, nil, nil
@@ -34,5 +36,9 @@ func (a *APIKey) Authenticate(ctx context.Context, header http.Header) (*irn.IRN
 		return nil, nil, err
 	}
 
-	return principalIRN, authorizationHeader, nil
+	return &Principal{
+		IRN:           principalIRN,
+		Kind:          PrincipalKindServiceAccount,
+		Authenticator: apiKeyAuthenticatorName,
+	}, authorizationHeader, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -15,18 +16,42 @@ var ErrNoAuthContext = errors.New("no auth context")
 type AuthenticationClient interface {
 	// WithAuth creates http.Handler middleware for authenticating the incoming request by means of either OAuth 2.0 Access Token
 	// or "X-iamcore-API-Key" HTTP header. This handler should precede the application request handling in the handlers chain.
-	// It populates the request context with validated requester principal's IRN for further use.
+	// It populates the request context with validated requester Principal for further use.
 	// Returns 401 Unauthorized HTTP error in case of unauthorized access, and stops HTTP request propagation.
 	WithAuth(next http.Handler) http.Handler
 
-	// SetAPIKeyAuthorizationHeader sets "X-iamcore-API-Key" authentication header to HTTP request.
+	// SetAPIKeyAuthorizationHeader sets the service authentication header to HTTP request, i.e. "X-iamcore-API-Key" header
+	// of the configured API key, or "Authorization" header of the token source token, see ServiceAuthorizationHeader.
+	// The header is not set in case the credentials cannot be resolved, e.g. the API key is empty; the error is logged.
 	SetAPIKeyAuthorizationHeader(r *http.Request)
 
-	// GetAPIKeyAuthorizationHeader convenient method that returns "X-iamcore-API-Key" authentication header with configured API key as a value.
+	// GetAPIKeyAuthorizationHeader convenient method that returns the service authentication header, as set by SetAPIKeyAuthorizationHeader.
+	// The header is empty in case the credentials cannot be resolved; use ServiceAuthorizationHeader to handle the error.
 	GetAPIKeyAuthorizationHeader() http.Header
 
 	// GetPrincipalAuthorizationHeader extracts and returns principal's authorization header from the request context.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrNoAuthContext error in case the context carries no principal.
+	// Returns ErrCredentialsNotForwardable error in case the principal is authenticated by mutual TLS client certificate.
 	GetPrincipalAuthorizationHeader(ctx context.Context) (http.Header, error)
+
+	// ServiceAuthorizationHeader returns the header authenticating the service itself: "Authorization" header with access token
+	// of the source configured by WithTokenSource, or "X-iamcore-API-Key" header otherwise.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case the token source credentials are rejected.
+	ServiceAuthorizationHeader(ctx context.Context) (http.Header, error)
+
+	// ServiceTransport returns http.RoundTripper authenticating outbound requests with ServiceAuthorizationHeader,
+	// replacing any credentials the requests carry. The requests rejected with 401 Unauthorized are retried with
+	// the previous API key during the grace period of RotatingCredentialProvider.
+	// The base transport is returned as is in case SDK is disabled.
+	ServiceTransport(base http.RoundTripper) http.RoundTripper
+
+	// HandleError writes the error response by means of the configured ErrorHandler, the same way WithAuth does.
+	// It is intended for application authorization middlewares, e.g. to respond to ErrForbidden returned by Authorize.
+	HandleError(w http.ResponseWriter, r *http.Request, err error)
 }
 
 // contextKeyType is a context.Context key type.
@@ -34,7 +59,7 @@ type contextKeyType int
 
 const (
 	principalAuthorizationHeaderKey contextKeyType = 0
-	principalIRNKey                 contextKeyType = 1
+	principalKey                    contextKeyType = 1
 )
 
 func (c *client) WithAuth(next http.Handler) http.Handler {
@@ -45,26 +70,23 @@ func (c *client) WithAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		var unauthenticatedErr error
+
 		for i := range c.authenticators {
-			principal, authorizationHeader, err := c.authenticators[i].Authenticate(r.Context(), r.Header)
+			principal, authorizationHeader, err := authenticateRequest(c.authenticators[i], r)
 			switch {
-			case err != nil && errors.Is(err, ErrUnauthenticated):
-				writeResponseMessage(w, http.StatusUnauthorized, err.Error())
+			case err != nil && errors.Is(err, ErrUnauthenticated) && c.tryAllAuthenticators:
+				unauthenticatedErr = err
 
-				return
+				continue
 			case err != nil:
-				writeResponseMessage(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+				c.HandleError(w, r, err)
 
 				return
 			}
 
 			if principal != nil {
-				ctx := r.Context()
-
-				ctx = context.WithValue(ctx, principalIRNKey, principal)
-				ctx = context.WithValue(ctx, principalAuthorizationHeaderKey, authorizationHeader)
-
-				r = r.WithContext(ctx)
+				r = r.WithContext(withPrincipal(r.Context(), principal, authorizationHeader))
 
 				// Pass control to the next handler
 				next.ServeHTTP(w, r)
@@ -73,19 +95,91 @@ func (c *client) WithAuth(next http.Handler) http.Handler {
 			}
 		}
 
-		writeResponseMessage(w, http.StatusUnauthorized, "Failed to authenticate request with any of available authenticators")
+		if unauthenticatedErr != nil {
+			c.HandleError(w, r, unauthenticatedErr)
+
+			return
+		}
+
+		if c.anonymousPolicy != nil {
+			principal, err := c.anonymousPolicy.authenticate(r, c.iamcoreClient)
+			if err != nil {
+				c.HandleError(w, r, err)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal, nil)))
+
+			return
+		}
+
+		c.HandleError(w, r, fmt.Errorf("failed to authenticate request with any of available authenticators: %w", ErrNoCredentials))
 	})
 }
 
-// SetAPIKeyAuthorizationHeader convenient method for setting "X-iamcore-API-Key" authentication header with configured API key as a value
-// into the provided request.
+// SetAPIKeyAuthorizationHeader convenient method for setting the service authentication header into the provided request,
+// see ServiceAuthorizationHeader. The header is not set in case the credentials cannot be resolved.
 func (c *client) SetAPIKeyAuthorizationHeader(r *http.Request) {
-	r.Header.Set(apiKeyHeaderName, c.apiKey)
+	for name, values := range c.currentServiceAuthorizationHeader(r.Context()) {
+		r.Header.Set(name, values[0])
+	}
 }
 
-// GetAPIKeyAuthorizationHeader convenient method that returns "X-iamcore-API-Key" authentication header with configured API key as a value.
+// GetAPIKeyAuthorizationHeader convenient method that returns the service authentication header, see ServiceAuthorizationHeader.
+// The header is empty in case the credentials cannot be resolved.
 func (c *client) GetAPIKeyAuthorizationHeader() http.Header {
-	return http.Header{apiKeyHeaderName: {c.apiKey}}
+	return c.currentServiceAuthorizationHeader(context.Background())
+}
+
+// currentServiceAuthorizationHeader resolves the service authentication header; empty if SDK is disabled or
+// the credentials cannot be resolved, e.g. the API key is empty.
+func (c *client) currentServiceAuthorizationHeader(ctx context.Context) http.Header {
+	header, err := c.ServiceAuthorizationHeader(ctx)
+	if err != nil {
+		if !errors.Is(err, ErrSDKDisabled) {
+			log.Printf("Error resolving iamcore service credentials: %v", err)
+		}
+
+		return http.Header{}
+	}
+
+	return header
+}
+
+func (c *client) ServiceAuthorizationHeader(ctx context.Context) (http.Header, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	if c.tokenSource != nil {
+		return tokenSourceCredentials(c.tokenSource)(ctx)
+	}
+
+	apiKey, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.Header{apiKeyHeaderName: {apiKey}}, nil
+}
+
+func (c *client) ServiceTransport(base http.RoundTripper) http.RoundTripper {
+	if c.disabled {
+		return base
+	}
+
+	transport := &serviceCredentialsRoundTripper{base: base, credentials: c.ServiceAuthorizationHeader}
+
+	if rotating, ok := c.credentials.(RotatingCredentialProvider); ok && c.tokenSource == nil {
+		transport.fallback = func(ctx context.Context) (http.Header, bool) {
+			previous, ok := rotating.PreviousAPIKey(ctx)
+
+			return http.Header{apiKeyHeaderName: {previous}}, ok
+		}
+	}
+
+	return transport
 }
 
 // GetPrincipalAuthorizationHeader extracts and returns principal's authorization header from the request context.
@@ -99,47 +193,51 @@ func (c *client) GetPrincipalAuthorizationHeader(ctx context.Context) (http.Head
 		return nil, ErrNoAuthContext
 	}
 
+	if principal, err := PrincipalFromContext(ctx); err == nil && principal.Authenticator == mtlsAuthenticatorName {
+		return nil, ErrCredentialsNotForwardable
+	}
+
 	return authorizationHeader, nil
 }
 
 // PrincipalIRN extracts and returns principal's IRN from the request context.
 func PrincipalIRN(ctx context.Context) (*irn.IRN, error) {
-	principal, ok := ctx.Value(principalIRNKey).(*irn.IRN)
-	if !ok {
-		return nil, ErrNoAuthContext
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return principal, nil
+	return principal.IRN, nil
 }
 
 // AccountID extracts and returns principal's account ID from the request context.
 func AccountID(ctx context.Context) (string, error) {
-	principal, ok := ctx.Value(principalIRNKey).(*irn.IRN)
-	if !ok {
-		return "", ErrNoAuthContext
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	return principal.GetAccountID(), nil
+	return principal.IRN.GetAccountID(), nil
 }
 
 // TenantID extracts and returns principal's tenant ID from the request context.
 func TenantID(ctx context.Context) (string, error) {
-	principal, ok := ctx.Value(principalIRNKey).(*irn.IRN)
-	if !ok {
-		return "", ErrNoAuthContext
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	return principal.GetTenantID(), nil
+	return principal.IRN.GetTenantID(), nil
 }
 
 // Path extracts and returns principal's path from the request context.
 func Path(ctx context.Context) (string, error) {
-	principal, ok := ctx.Value(principalIRNKey).(*irn.IRN)
-	if !ok {
-		return "", ErrNoAuthContext
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	return principal.GetPath(), nil
+	return principal.IRN.GetPath(), nil
 }
 
 func writeResponseMessage(w http.ResponseWriter, statusCode int, message string) {
@@ -0,0 +1,176 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// stubAuthenticator returns a fixed result and counts its invocations.
+type stubAuthenticator struct {
+	principal *Principal
+	err       error
+	calls     int
+}
+
+func (s *stubAuthenticator) Authenticate(_ context.Context, _ http.Header) (*Principal, http.Header, error) {
+	s.calls++
+
+	return s.principal, nil, s.err
+}
+
+func newTestClient(t *testing.T, opts ...Option) *client {
+	t.Helper()
+
+	c, err := NewClient("api-key", "http://iamcore.invalid", false, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return c.(*client)
+}
+
+// newServerTestClient creates the client of iamcore stubbed by the test server.
+func newServerTestClient(t *testing.T, server *httptest.Server, opts ...Option) *client {
+	t.Helper()
+
+	c, err := NewClient("api-key", server.URL, false, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return c.(*client)
+}
+
+func serveWithAuth(c *client) (*httptest.ResponseRecorder, *Principal) {
+	return serveRequestWithAuth(c, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func serveRequestWithAuth(c *client, request *http.Request) (*httptest.ResponseRecorder, *Principal) {
+	var principal *Principal
+
+	handler := c.WithAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	return recorder, principal
+}
+
+func TestWithAuthCustomChainOrder(t *testing.T) {
+	skipped := &stubAuthenticator{}
+	first := &stubAuthenticator{principal: &Principal{Authenticator: "first"}}
+	second := &stubAuthenticator{principal: &Principal{Authenticator: "second"}}
+
+	c := newTestClient(t, WithAuthenticators(func(*ServerClient) []Authenticator {
+		return []Authenticator{skipped, first, second}
+	}))
+
+	recorder, principal := serveWithAuth(c)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if principal == nil || principal.Authenticator != "first" {
+		t.Fatalf("expected principal from the first matching authenticator, got %+v", principal)
+	}
+	if skipped.calls != 1 || second.calls != 0 {
+		t.Fatalf("unexpected authenticator calls: skipped=%d second=%d", skipped.calls, second.calls)
+	}
+}
+
+func TestWithAuthNilAuthenticatorsFactory(t *testing.T) {
+	if _, err := NewClient("api-key", "http://iamcore.invalid", false, WithAuthenticators(nil)); !errors.Is(err, ErrNilAuthenticatorsFactory) {
+		t.Fatalf("expected ErrNilAuthenticatorsFactory, got %v", err)
+	}
+}
+
+func TestWithAuthStopOnFirstCredential(t *testing.T) {
+	rejecting := &stubAuthenticator{err: fmt.Errorf("invalid token: %w", ErrUnauthenticated)}
+	fallback := &stubAuthenticator{principal: &Principal{Authenticator: "fallback"}}
+	factory := WithAuthenticators(func(*ServerClient) []Authenticator {
+		return []Authenticator{rejecting, fallback}
+	})
+
+	recorder, _ := serveWithAuth(newTestClient(t, factory))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 when stopping on first credential, got %d", recorder.Code)
+	}
+	if fallback.calls != 0 {
+		t.Fatalf("expected fallback authenticator not to be called, got %d calls", fallback.calls)
+	}
+
+	recorder, principal := serveWithAuth(newTestClient(t, factory, WithStopOnFirstCredential(false)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200 when trying all authenticators, got %d", recorder.Code)
+	}
+	if principal == nil || principal.Authenticator != "fallback" {
+		t.Fatalf("expected principal from fallback authenticator, got %+v", principal)
+	}
+}
+
+func TestWithAuthAnonymousPolicy(t *testing.T) {
+	anonymousIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "", nil, "user", nil, "anonymous")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := newTestClient(t, WithAnonymousPolicy(AnonymousPolicy{
+		Mode:         AnonymousStatic,
+		PrincipalIRN: anonymousIRN,
+		Methods:      []string{http.MethodGet},
+		PathPatterns: []string{"/public/**", "/health"},
+	}))
+
+	recorder, principal := serveRequestWithAuth(c, httptest.NewRequest(http.MethodGet, "/public/docs/index.html", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200 for allowed anonymous request, got %d", recorder.Code)
+	}
+	if principal == nil || !principal.IsAnonymous() || principal.IRN != anonymousIRN {
+		t.Fatalf("expected configured anonymous principal, got %+v", principal)
+	}
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/health", nil),
+		httptest.NewRequest(http.MethodGet, "/private", nil),
+	} {
+		if recorder, _ = serveRequestWithAuth(c, request); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for %s %s, got %d", request.Method, request.URL.Path, recorder.Code)
+		}
+	}
+}
+
+func TestAnonymousPolicyAllowsPath(t *testing.T) {
+	policy := &AnonymousPolicy{PathPatterns: []string{"/public/**", "/tenants/*/assets/**"}}
+
+	for requestPath, want := range map[string]bool{
+		"/public":                     true,
+		"/public/docs/index.html":     true,
+		"/public/../admin":            false,
+		"/public/%2e%2e/admin":        false,
+		"/publications":               false,
+		"/tenants/acme/assets/logo":   true,
+		"/tenants/acme/private/key":   false,
+		"/tenants/acme/assets/../key": false,
+	} {
+		request := httptest.NewRequest(http.MethodGet, requestPath, nil)
+
+		if got := policy.allowsPath(request.URL.Path); got != want {
+			t.Errorf("%s: got %t, want %t", requestPath, got, want)
+		}
+	}
+}
+
+func TestWithAuthAnonymousReject(t *testing.T) {
+	c := newTestClient(t, WithAnonymousPolicy(AnonymousPolicy{Mode: AnonymousReject}))
+
+	if recorder, _ := serveWithAuth(c); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for rejected anonymous request, got %d", recorder.Code)
+	}
+}
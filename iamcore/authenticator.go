@@ -3,10 +3,42 @@ package iamcore
 import (
 	"context"
 	"net/http"
-
-	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
+// Authenticator authenticates the incoming request within WithAuth authenticator chain.
+//
+// Authenticate returns nil principal and nil error in case the request carries no credentials the authenticator handles,
+// so that the next authenticator in the chain is tried.
+// Returns error wrapping ErrUnauthenticated in case the credentials are present but invalid.
 type Authenticator interface {
-	Authenticate(ctx context.Context, header http.Header) (principalIRN *irn.IRN, authorizationHeader http.Header, err error)
+	Authenticate(ctx context.Context, header http.Header) (principal *Principal, authorizationHeader http.Header, err error)
+}
+
+// RequestAuthenticator is an Authenticator that needs the whole incoming request rather than its header only,
+// e.g. request method or TLS connection state. WithAuth calls AuthenticateRequest for authenticators implementing it.
+type RequestAuthenticator interface {
+	Authenticator
+	AuthenticateRequest(r *http.Request) (principal *Principal, authorizationHeader http.Header, err error)
+}
+
+// AuthenticatorsFactory builds the authenticator chain. The iamcore server client is passed so that the built-in
+// authenticators (NewBearer, NewAPIKey, NewEmptyHeader) can be combined with custom ones.
+type AuthenticatorsFactory func(iamcore *ServerClient) []Authenticator
+
+// DefaultAuthenticators returns the default authenticator chain: Bearer, APIKey, EmptyHeader.
+func DefaultAuthenticators(iamcore *ServerClient) []Authenticator {
+	return []Authenticator{
+		NewBearer(iamcore),
+		NewAPIKey(iamcore),
+		NewEmptyHeader(iamcore),
+	}
+}
+
+// authenticateRequest authenticates the request with the authenticator, passing the whole request if the authenticator supports it.
+func authenticateRequest(authenticator Authenticator, r *http.Request) (*Principal, http.Header, error) {
+	if requestAuthenticator, ok := authenticator.(RequestAuthenticator); ok {
+		return requestAuthenticator.AuthenticateRequest(r)
+	}
+
+	return authenticator.Authenticate(r.Context(), r.Header)
 }
@@ -3,8 +3,11 @@ package iamcore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/queryfilter"
 	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
@@ -61,6 +64,19 @@ type AuthorizationClient interface {
 	// Returns ErrBadRequest error in case of invalid request.
 	AuthorizationDBQueryFilter(ctx context.Context, authorizationHeader http.Header, action, database string) (string, error)
 
+	// AuthorizationQueryFilter retrieves the typed authorization filter over resources of the specified type, to which user has
+	// the requested action granted. Unlike AuthorizationDBQueryFilter, the filter is rendered by the application for its own
+	// database engine and schema, e.g. by queryfilter.Apply, with all the values passed as query arguments.
+	//
+	// The filter is built from the resources evaluated on the resource type, as returned by Authorize without resource IDs:
+	// granted resource IDs match by ID, resources granted by a trailing wildcard match by IRN prefix.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthorized access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to any resources.
+	// Returns ErrBadRequest error in case of invalid request.
+	AuthorizationQueryFilter(ctx context.Context, authorizationHeader http.Header, application, resourceType, action string) (*queryfilter.Filter, error)
+
 	// EvaluateActionsOnIRNs evaluates a list of actions against a list of IRNs and returns a map
 	// associating each action with its corresponding permitted and prohibited IRNs matching requested IRNs.
 	//
@@ -71,12 +87,48 @@ type AuthorizationClient interface {
 
 	// EvaluateActionsOnIRNsByPrincipal evaluates actions on a resource list for a specific principal.
 	// Returns a map associating each action with its corresponding permitted and prohibited IRNs.
+	// The resources are located in the root path of the principal's tenant; see EvaluateActionsOnResourcesByPrincipal.
 	//
 	// Returns ErrSDKDisabled error in case SDK is disabled.
 	// Returns ErrUnauthenticated error in case of unauthorized access.
 	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions.
 	// Returns ErrBadRequest error in case of invalid request.
 	EvaluateActionsOnIRNsByPrincipal(ctx context.Context, authorizationHeader http.Header, application, resourceType string, principal *irn.IRN, actions, resourceIDs []string) (map[string]*AllowedAndDeniedIRNs, error)
+
+	// EvaluateActionsOnResourcesByPrincipal evaluates actions on resources located at the path in the principal's tenant
+	// against the policies of the principal, rather than of the authenticated one.
+	// Returns a map associating each action with its corresponding permitted and prohibited IRNs.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthorized access.
+	// Returns ErrForbidden error in case authenticated principal is not privileged to evaluate policies of other principals.
+	// Returns ErrBadRequest error in case of invalid request.
+	EvaluateActionsOnResourcesByPrincipal(ctx context.Context, authorizationHeader http.Header, principal *irn.IRN,
+		application, resourceType, resourcePath string, actions, resourceIDs []string) (map[string]*AllowedAndDeniedIRNs, error)
+
+	// ExplainAuthorization evaluates actions on resources and returns per-resource, per-action decisions
+	// with IRNs of the policies contributed to each decision, e.g. to find out why the principal was denied.
+	// The actions are evaluated against the policies of the principal, if set, and of the authenticated one otherwise.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthorized access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to evaluate policies,
+	// or is not privileged to evaluate policies of other principals.
+	// Returns ErrBadRequest error in case of invalid request.
+	ExplainAuthorization(ctx context.Context, authorizationHeader http.Header, principal *irn.IRN, application string, resources []*irn.IRN,
+		actions []string) (*AuthorizationExplanation, error)
+
+	// PermissionMatrix evaluates actions on resources in one call and tells whether each action is granted on each resource,
+	// e.g. to decide which controls to show for a page of items.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthorized access.
+	// Returns ErrBadRequest error in case of invalid request.
+	PermissionMatrix(ctx context.Context, authorizationHeader http.Header, resourceRef ResourceRef, resourceIDs, actions []string) (PermissionMatrix, error)
+
+	// PermissionMatrixHandler creates http.Handler exposing PermissionMatrix of the authenticated principal to SPAs.
+	// The handler must be wrapped with WithAuth.
+	PermissionMatrixHandler(resolve ResourceRefResolver) http.Handler
 }
 
 func (c *client) Authorize(ctx context.Context, authorizationHeader http.Header, accountID, application,
@@ -155,6 +207,21 @@ func (c *client) AuthorizationDBQueryFilter(ctx context.Context, authorizationHe
 	return c.iamcoreClient.AuthorizationDBQueryFilter(ctx, authorizationHeader, action, database)
 }
 
+func (c *client) AuthorizationQueryFilter(ctx context.Context, authorizationHeader http.Header, application, resourceType, action string) (
+	*queryfilter.Filter, error,
+) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	resourceIRNs, err := c.iamcoreClient.AuthorizedOnResourceType(ctx, authorizationHeader, application, "", resourceType, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildQueryFilter(resourceIRNs), nil
+}
+
 func (c *client) EvaluateActionsOnIRNs(ctx context.Context, authorizationHeader http.Header, actions []string, irns []*irn.IRN) (
 	map[string]*AllowedAndDeniedIRNs, error,
 ) {
@@ -167,17 +234,27 @@ func (c *client) EvaluateActionsOnIRNs(ctx context.Context, authorizationHeader
 
 func (c *client) EvaluateActionsOnIRNsByPrincipal(ctx context.Context, authorizationHeader http.Header,
 	application, resourceType string, principal *irn.IRN, actions, resourceIDs []string,
+) (map[string]*AllowedAndDeniedIRNs, error) {
+	return c.EvaluateActionsOnResourcesByPrincipal(ctx, authorizationHeader, principal, application, resourceType, "", actions, resourceIDs)
+}
+
+func (c *client) EvaluateActionsOnResourcesByPrincipal(ctx context.Context, authorizationHeader http.Header, principal *irn.IRN,
+	application, resourceType, resourcePath string, actions, resourceIDs []string,
 ) (map[string]*AllowedAndDeniedIRNs, error) {
 	if c.disabled {
 		return nil, ErrSDKDisabled
 	}
 
-	resourceIRNs, err := buildResourceIRNs(principal.GetAccountID(), application, principal.GetTenantID(), resourceType, "", resourceIDs)
+	if principal == nil {
+		return nil, fmt.Errorf("principal is required: %w", ErrBadRequest)
+	}
+
+	resourceIRNs, err := buildResourceIRNs(principal.GetAccountID(), application, principal.GetTenantID(), resourceType, resourcePath, resourceIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	responseDTO, err := c.iamcoreClient.EvaluateDebugResources(ctx, authorizationHeader, application, resourceIRNs, actions)
+	responseDTO, err := c.iamcoreClient.EvaluateDebugResourcesAsPrincipal(ctx, authorizationHeader, principal, application, resourceIRNs, actions)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +300,27 @@ func buildResourceIRNs(accountID, application, tenantID, resourceType, resourceP
 	return resourceIRNs, nil
 }
 
+// buildQueryFilter converts resources, evaluated on the resource type, into the query filter.
+// The resource IRN having a trailing wildcard is converted into the IRN prefix, the one having a wildcard elsewhere
+// cannot be matched by a prefix, so it is skipped, i.e. the filter never grants more than evaluated.
+func buildQueryFilter(resourceIRNs []*irn.IRN) *queryfilter.Filter {
+	filter := &queryfilter.Filter{}
+
+	for _, resourceIRN := range resourceIRNs {
+		resourceIRNString := resourceIRN.String()
+
+		wildcardIndex := strings.Index(resourceIRNString, "*")
+		switch {
+		case wildcardIndex < 0:
+			filter.ResourceIDs = append(filter.ResourceIDs, resourceIRN.GetResourceID())
+		case wildcardIndex == len(resourceIRNString)-1:
+			filter.IRNPrefixes = append(filter.IRNPrefixes, resourceIRNString[:wildcardIndex])
+		}
+	}
+
+	return filter
+}
+
 func getResourceIDs(resourceIRNs []*irn.IRN) []string {
 	resourceIDs := make([]string, len(resourceIRNs))
 
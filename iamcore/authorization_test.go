@@ -0,0 +1,87 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEvaluateActionsOnResourcesByPrincipal(t *testing.T) {
+	principal := mustIRN(t, "acme", "", "john")
+
+	var forbidden bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := &struct {
+			Principal string   `json:"principal"`
+			Resources []string `json:"resources"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(requestDTO)
+
+		if requestDTO.Principal != principal.Base64() {
+			t.Errorf("expected principal %s, got %q", principal.Base64(), requestDTO.Principal)
+		}
+
+		resource := mustIRN(t, "acme", "/eu/lab", "d1")
+		if len(requestDTO.Resources) != 1 || requestDTO.Resources[0] != resource.Base64() {
+			t.Errorf("unexpected resources %v", requestDTO.Resources)
+		}
+
+		if forbidden {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"not allowed to evaluate policies of other principals"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":[{"id":"d1","irn":"irn:rc73dbh7q0:fleet:acme:device/eu/lab/d1","decision":"allow",` +
+			`"actions":[{"action":"fleet:device:read","decision":"allow"}]}]}`))
+	}))
+	defer server.Close()
+
+	c := newServerTestClient(t, server)
+
+	result, err := c.EvaluateActionsOnResourcesByPrincipal(context.Background(), nil, principal, "fleet", "device", "/eu/lab",
+		[]string{"fleet:device:read"}, []string{"d1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result["fleet:device:read"].Allowed) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	forbidden = true
+
+	_, err = c.EvaluateActionsOnResourcesByPrincipal(context.Background(), nil, principal, "fleet", "device", "/eu/lab",
+		[]string{"fleet:device:read"}, []string{"d1"})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestAuthorizationQueryFilter(t *testing.T) {
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + evaluateOnResourceTypePath: {http.StatusOK, `{"data":["irn:rc73dbh7q0:fleet:acme::device/eu/d1",` +
+			`"irn:rc73dbh7q0:fleet:acme::device/us/*","irn:rc73dbh7q0:fleet:acme::device/*/d2"]}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+
+	filter, err := c.AuthorizationQueryFilter(context.Background(), nil, "fleet", "device", "fleet:device:read")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filter.Unrestricted || len(filter.ResourceIDs) != 1 || filter.ResourceIDs[0] != "d1" ||
+		len(filter.IRNPrefixes) != 1 || filter.IRNPrefixes[0] != "irn:rc73dbh7q0:fleet:acme::device/us/" {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"action":"fleet:device:read","resourceType":"device","application":"fleet","tenantID":""}` {
+		t.Fatalf("unexpected evaluate request %s", body)
+	}
+}
@@ -2,14 +2,21 @@ package iamcore
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
-const authorizationHeaderName = "Authorization"
+const (
+	authorizationHeaderName = "Authorization"
+
+	bearerAuthenticatorName = "bearer"
+)
 
 type Bearer struct {
 	iamcore *ServerClient
@@ -21,7 +28,7 @@ func NewBearer(iamcore *ServerClient) *Bearer {
 	}
 }
 
-func (b *Bearer) Authenticate(ctx context.Context, header http.Header) (*irn.IRN, http.Header, error) {
+func (b *Bearer) Authenticate(ctx context.Context, header http.Header) (*Principal, http.Header, error) {
 	bearerTokenHeader := header.Get(authorizationHeaderName)
 	if len(bearerTokenHeader) == 0 {
 		return nil, nil, nil
@@ -29,17 +36,79 @@ func (b *Bearer) Authenticate(ctx context.Context, header http.Header) (*irn.IRN
 
 	rawAccessTokenParts := strings.Split(bearerTokenHeader, " ")
 	if len(rawAccessTokenParts) != 2 || strings.ToLower(rawAccessTokenParts[0]) != "bearer" {
-		return nil, nil, fmt.Errorf("unexpected Authorization header format, must be 'Bearer <access-token>': %w", ErrUnauthenticated)
+		return nil, nil, fmt.Errorf("unexpected Authorization header format, must be 'Bearer <access-token>': %w", ErrMalformedCredentials)
 	}
 
+	return authenticateAccessToken(ctx, b.iamcore, rawAccessTokenParts[1], bearerAuthenticatorName)
+}
+
+// authenticateAccessToken resolves the principal of OAuth 2.0 Access Token on iamcore and returns it along with
+// "Authorization: Bearer" header for further propagation.
+func authenticateAccessToken(ctx context.Context, iamcore *ServerClient, accessToken, authenticatorName string) (*Principal, http.Header, error) {
 	authorizationHeader := http.Header{
-		authorizationHeaderName: {bearerTokenHeader},
+		authorizationHeaderName: {"Bearer " + accessToken},
 	}
 
-	principalIRN, err := b.iamcore.GetPrincipalIRN(ctx, authorizationHeader)
+	principalIRN, err := iamcore.GetPrincipalIRN(ctx, authorizationHeader)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return principalIRN, authorizationHeader, nil
+	if principalIRN == nil {
+		return nil, nil, fmt.Errorf("access token is not issued to any principal: %w", ErrUnauthenticated)
+	}
+
+	principal := &Principal{
+		IRN:           principalIRN,
+		Kind:          principalKindOf(principalIRN),
+		Authenticator: authenticatorName,
+	}
+
+	// The token has just been validated by iamcore, so its claims are decoded without signature verification.
+	if claims, ok := decodeJWTClaims(accessToken); ok {
+		principal.Claims = claims
+		principal.ExpiresAt = claimsExpiry(claims)
+	}
+
+	return principal, authorizationHeader, nil
+}
+
+// principalKindOf returns the kind of the principal by its IRN resource type, as access tokens are issued
+// both to users and to service accounts, e.g. by means of client credentials grant.
+func principalKindOf(principalIRN *irn.IRN) PrincipalKind {
+	if principalIRN.GetResourceType() == string(PrincipalTypeUser) {
+		return PrincipalKindUser
+	}
+
+	return PrincipalKindServiceAccount
+}
+
+// decodeJWTClaims decodes the payload of a JWT. Returns false if the token is not a JWT.
+func decodeJWTClaims(token string) (map[string]interface{}, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+
+	claims := make(map[string]interface{})
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// claimsExpiry returns the time of the "exp" claim; zero if absent.
+func claimsExpiry(claims map[string]interface{}) time.Time {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(exp), 0)
 }
@@ -0,0 +1,74 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDecodeJWTClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"johndoe","exp":1700000000}`))
+
+	claims, ok := decodeJWTClaims("eyJhbGciOiJSUzI1NiJ9." + payload + ".signature")
+	if !ok {
+		t.Fatal("expected JWT claims to be decoded")
+	}
+	if claims["sub"] != "johndoe" {
+		t.Fatalf("unexpected sub claim: %v", claims["sub"])
+	}
+	if got := claimsExpiry(claims); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected expiry: %v", got)
+	}
+}
+
+func TestDecodeJWTClaimsOpaqueToken(t *testing.T) {
+	if _, ok := decodeJWTClaims("opaque-access-token"); ok {
+		t.Fatal("expected opaque token not to be decoded as JWT")
+	}
+	if got := claimsExpiry(map[string]interface{}{}); !got.IsZero() {
+		t.Fatalf("expected zero expiry without exp claim, got %v", got)
+	}
+}
+
+func TestBearerPrincipalKind(t *testing.T) {
+	principalIRNs := map[string]string{
+		"Bearer user-token":   "irn:rc73dbh7q0:iamcore:acme::user/johndoe",
+		"Bearer client-token": "irn:rc73dbh7q0:iamcore:acme::api-key/billing",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":"` + principalIRNs[r.Header.Get(authorizationHeaderName)] + `"}`))
+	}))
+	defer server.Close()
+
+	bearer := NewBearer(NewServerClient(server.URL, server.Client()))
+
+	for header, want := range map[string]PrincipalKind{"Bearer user-token": PrincipalKindUser, "Bearer client-token": PrincipalKindServiceAccount} {
+		principal, _, err := bearer.Authenticate(context.Background(), http.Header{authorizationHeaderName: {header}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if principal.Kind != want {
+			t.Errorf("%s: got %s principal, want %s", header, principal.Kind, want)
+		}
+	}
+}
+
+func TestBearerWithoutPrincipalIRN(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":null}`))
+	}))
+	defer server.Close()
+
+	bearer := NewBearer(NewServerClient(server.URL, server.Client()))
+
+	_, _, err := bearer.Authenticate(context.Background(), http.Header{authorizationHeaderName: {"Bearer token"}})
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
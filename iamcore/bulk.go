@@ -0,0 +1,173 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+const defaultBulkConcurrency = 8
+
+// ResourceDescriptor describes a resource of bulk operations.
+type ResourceDescriptor struct {
+	Application  string
+	TenantID     string
+	ResourceType string
+	Path         string
+	ID           string
+	// PoolIDs the resource is attached to on creation.
+	PoolIDs []string
+}
+
+// BulkOptions configures bulk operations.
+type BulkOptions struct {
+	// Concurrency is the maximum number of concurrent requests to iamcore; 8 by default.
+	Concurrency int
+	// Idempotent makes already existing resources on creation and missing resources on deletion count as succeeded.
+	Idempotent bool
+}
+
+// BulkStatus is the outcome of a bulk operation item.
+type BulkStatus string
+
+const (
+	BulkStatusCreated  BulkStatus = "created"
+	BulkStatusDeleted  BulkStatus = "deleted"
+	BulkStatusConflict BulkStatus = "conflict"
+	BulkStatusNotFound BulkStatus = "not-found"
+	BulkStatusFailed   BulkStatus = "failed"
+)
+
+// BulkItemResult is the result of a bulk operation item.
+type BulkItemResult struct {
+	// Index of the item in the requested resources.
+	Index    int
+	Resource ResourceDescriptor
+	Status   BulkStatus
+	// Err is the item failure, usually *APIError; nil for created and deleted items.
+	Err error
+	// Succeeded reports whether the item counts as succeeded according to BulkOptions.
+	Succeeded bool
+}
+
+// BulkReport is the per-item report of a bulk operation, in the order of the requested resources.
+type BulkReport struct {
+	Results []*BulkItemResult
+}
+
+// Failed returns results of the items that did not succeed.
+func (r *BulkReport) Failed() []*BulkItemResult {
+	failed := make([]*BulkItemResult, 0)
+
+	for _, result := range r.Results {
+		if !result.Succeeded {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+func (c *client) CreateResources(ctx context.Context, authorizationHeader http.Header, resources []ResourceDescriptor, options BulkOptions) (
+	*BulkReport, error,
+) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return runBulk(ctx, resources, options, func(ctx context.Context, resource ResourceDescriptor) *BulkItemResult {
+		err := c.CreateResourceWithPools(ctx, authorizationHeader,
+			resource.Application, resource.TenantID, resource.ResourceType, resource.Path, resource.ID, resource.PoolIDs)
+
+		switch {
+		case err == nil:
+			return &BulkItemResult{Status: BulkStatusCreated, Succeeded: true}
+		case errors.Is(err, ErrConflict):
+			return &BulkItemResult{Status: BulkStatusConflict, Err: err, Succeeded: options.Idempotent}
+		default:
+			return &BulkItemResult{Status: BulkStatusFailed, Err: err}
+		}
+	}), nil
+}
+
+func (c *client) DeleteResources(ctx context.Context, authorizationHeader http.Header, resources []ResourceDescriptor, options BulkOptions) (
+	*BulkReport, error,
+) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	principalIRN, err := c.iamcoreClient.GetPrincipalIRN(ctx, authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return runBulk(ctx, resources, options, func(ctx context.Context, resource ResourceDescriptor) *BulkItemResult {
+		resourceIRN, err := irn.NewIRN(principalIRN.GetAccountID(), resource.Application, resource.TenantID, nil,
+			resource.ResourceType, irn.SplitPath(resource.Path), resource.ID)
+		if err == nil {
+			err = c.iamcoreClient.DeleteResource(ctx, authorizationHeader, resourceIRN)
+		}
+
+		switch {
+		case err == nil:
+			return &BulkItemResult{Status: BulkStatusDeleted, Succeeded: true}
+		case errors.Is(err, ErrNotFound):
+			return &BulkItemResult{Status: BulkStatusNotFound, Err: err, Succeeded: options.Idempotent}
+		default:
+			return &BulkItemResult{Status: BulkStatusFailed, Err: err}
+		}
+	}), nil
+}
+
+// runBulk applies the operation to every resource with bounded concurrency. Items not started before
+// the context is done fail with the context error.
+func runBulk(ctx context.Context, resources []ResourceDescriptor, options BulkOptions,
+	operation func(ctx context.Context, resource ResourceDescriptor) *BulkItemResult,
+) *BulkReport {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	report := &BulkReport{
+		Results: make([]*BulkItemResult, len(resources)),
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i := range resources {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			report.Results[i] = &BulkItemResult{Status: BulkStatusFailed, Err: ctx.Err()}
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			report.Results[i] = operation(ctx, resources[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, result := range report.Results {
+		result.Index = i
+		result.Resource = resources[i]
+	}
+
+	return report
+}
@@ -0,0 +1,136 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// trackInFlight counts the request in flight and records the maximum; the returned function completes the request.
+func trackInFlight(inFlight, maxInFlight *int32) func() {
+	current := atomic.AddInt32(inFlight, 1)
+
+	for {
+		observed := atomic.LoadInt32(maxInFlight)
+		if current <= observed || atomic.CompareAndSwapInt32(maxInFlight, observed, current) {
+			break
+		}
+	}
+
+	return func() { atomic.AddInt32(inFlight, -1) }
+}
+
+func TestCreateResourcesReportsPerItem(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer trackInFlight(&inFlight, &maxInFlight)()
+
+		requestDTO := &CreateResourceRequestDTO{}
+		_ = json.NewDecoder(r.Body).Decode(requestDTO)
+
+		switch requestDTO.Name {
+		case "existing":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"resource already exists"}`))
+		case "broken":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid resource"}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	c := newServerTestClient(t, server)
+
+	resources := []ResourceDescriptor{{ID: "new"}, {ID: "existing"}, {ID: "broken"}, {ID: "other"}}
+
+	report, err := c.CreateResources(context.Background(), nil, resources, BulkOptions{Concurrency: 2, Idempotent: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantStatuses := []BulkStatus{BulkStatusCreated, BulkStatusConflict, BulkStatusFailed, BulkStatusCreated}
+	for i, result := range report.Results {
+		if result.Status != wantStatuses[i] || result.Resource.ID != resources[i].ID {
+			t.Fatalf("result %d: got %s for %q, want %s", i, result.Status, result.Resource.ID, wantStatuses[i])
+		}
+	}
+
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Resource.ID != "broken" {
+		t.Fatalf("expected only the broken resource to fail, got %+v", failed)
+	}
+
+	var apiError *APIError
+	if !errors.As(failed[0].Err, &apiError) || apiError.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected *APIError with 400 status, got %v", failed[0].Err)
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", got)
+	}
+}
+
+func TestDeleteResourcesReportsPerItem(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == userIRNPath {
+			_, _ = w.Write([]byte(`{"data":"irn:rc73dbh7q0:iamcore:acme::user/john"}`))
+
+			return
+		}
+
+		defer trackInFlight(&inFlight, &maxInFlight)()
+
+		switch {
+		case r.Method != http.MethodDelete:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"resource not found"}`))
+		case strings.HasSuffix(r.URL.Path, "/locked"):
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"forbidden"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := newServerTestClient(t, server)
+
+	resources := []ResourceDescriptor{
+		{ResourceType: "device", ID: "d1"}, {ResourceType: "device", ID: "missing"},
+		{ResourceType: "device", ID: "locked"}, {ResourceType: "device", ID: "d2"}, {ResourceType: "device", ID: "d3"},
+	}
+
+	report, err := c.DeleteResources(context.Background(), nil, resources, BulkOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantStatuses := []BulkStatus{BulkStatusDeleted, BulkStatusNotFound, BulkStatusFailed, BulkStatusDeleted, BulkStatusDeleted}
+	for i, result := range report.Results {
+		if result.Status != wantStatuses[i] || result.Index != i || result.Resource.ID != resources[i].ID {
+			t.Fatalf("result %d: got %s for %q, want %s", i, result.Status, result.Resource.ID, wantStatuses[i])
+		}
+	}
+
+	// Missing resources fail unless the deletion is idempotent.
+	failed := report.Failed()
+	if len(failed) != 2 || failed[0].Resource.ID != "missing" || !errors.Is(failed[1].Err, ErrForbidden) {
+		t.Fatalf("expected missing and locked resources to fail, got %+v", failed)
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", got)
+	}
+}
@@ -2,47 +2,65 @@ package iamcore
 
 import (
 	"log"
+	"net/http"
 )
 
 type Client interface {
 	AuthenticationClient
 	AuthorizationClient
 	ResourceManager
+	PoolManager
+	PolicyManager
+	Directory
+	Tenants
 }
 
 type client struct {
-	authenticators []Authenticator
-	iamcoreClient  *ServerClient
-	disabled       bool
+	authenticators       []Authenticator
+	tryAllAuthenticators bool
+	anonymousPolicy      *AnonymousPolicy
+	errorHandler         ErrorHandler
+	upstreamErrorStatus  int
+	iamcoreClient        *ServerClient
+	disabled             bool
 
-	apiKey string
+	credentials CredentialProvider
+	tokenSource TokenSource
 }
 
-func NewClient(apiKey, serverURL string, disabled bool) (Client, error) {
+func NewClient(apiKey, serverURL string, disabled bool, opts ...Option) (Client, error) {
 	if disabled {
 		log.Println("iamcore SDK is DISABLED")
 
 		return &client{
-			disabled: true,
+			disabled:            true,
+			errorHandler:        JSONErrorHandler,
+			upstreamErrorStatus: http.StatusInternalServerError,
 		}, nil
 	}
 
-	options, err := newOptions(apiKey, serverURL)
+	options, err := newOptions(apiKey, serverURL, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	iamcoreClient := NewServerClient(options.serverURL, newDefaultHTTPClient())
+	iamcoreClient := NewServerClient(options.serverURL, newHTTPClient(options.timeout, options.maxRetries, options.rootCAs))
+
+	authenticators := options.authenticatorsFactory(iamcoreClient)
+	if options.anonymousPolicy != nil {
+		authenticators = withoutEmptyHeader(authenticators)
+	}
 
 	return &client{
-		authenticators: []Authenticator{
-			NewBearer(iamcoreClient),
-			NewAPIKey(iamcoreClient),
-			NewEmptyHeader(iamcoreClient),
-		},
-		iamcoreClient: iamcoreClient,
-		disabled:      false,
-
-		apiKey: options.apiKey,
+		authenticators:       authenticators,
+		tryAllAuthenticators: options.tryAllAuthenticators,
+		anonymousPolicy:      options.anonymousPolicy,
+		errorHandler:         options.errorHandler,
+		upstreamErrorStatus:  options.upstreamErrorStatus,
+		iamcoreClient:        iamcoreClient,
+		disabled:             false,
+
+		credentials: options.credentials,
+		tokenSource: options.tokenSource,
 	}, nil
 }
@@ -0,0 +1,354 @@
+package iamcore
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	clientCredentialsGrantType = "client_credentials"
+	jwtBearerAssertionType     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	defaultTokenRefreshAhead = time.Minute
+	clientAssertionLifetime  = time.Minute
+)
+
+var ErrInvalidClientCredentials = errors.New("invalid client credentials configuration")
+
+// Token is OAuth 2.0 access token.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Scope       string
+	// ExpiresAt is zero in case the token lifetime is not known.
+	ExpiresAt time.Time
+}
+
+// TokenSource supplies valid access tokens, e.g. to authenticate the service on iamcore instead of API key.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// ClientCredentialsConfig configures ClientCredentialsTokenSource. The client authenticates either with the secret,
+// or with JWT assertion signed by the private key as defined by RFC 7523.
+type ClientCredentialsConfig struct {
+	// TokenURL is the token endpoint, e.g. iamcore server URL followed by OAuth2TokenPath.
+	TokenURL string
+	ClientID string
+	// ClientSecret authenticates the client with HTTP Basic authentication.
+	ClientSecret string
+	// PrivateKey signs the client assertion: *rsa.PrivateKey (RS256) or *ecdsa.PrivateKey with P-256 curve (ES256).
+	PrivateKey crypto.Signer
+	// KeyID is "kid" header of the client assertion, if set.
+	KeyID  string
+	Scopes []string
+	// HTTPClient calls the token endpoint; the default SDK HTTP client if not set, see Config.ClientCredentialsConfig for the configured one.
+	HTTPClient *http.Client
+	// RefreshAhead is the time before expiry when the token is refreshed; one minute by default.
+	RefreshAhead time.Duration
+}
+
+// ClientCredentialsTokenSource is TokenSource obtaining tokens by means of OAuth 2.0 client credentials grant.
+// The token is cached and refreshed ahead of expiry, the token of unknown lifetime is requested on each use;
+// it is safe for concurrent use.
+type ClientCredentialsTokenSource struct {
+	config ClientCredentialsConfig
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewClientCredentialsTokenSource creates ClientCredentialsTokenSource.
+//
+// Returns ErrInvalidClientCredentials error in case token URL or client ID is missing, neither or both of
+// client secret and private key are set, or the private key type is not supported.
+func NewClientCredentialsTokenSource(config ClientCredentialsConfig) (*ClientCredentialsTokenSource, error) {
+	if config.TokenURL == "" || config.ClientID == "" {
+		return nil, fmt.Errorf("token URL and client ID are required: %w", ErrInvalidClientCredentials)
+	}
+
+	if (config.ClientSecret == "") == (config.PrivateKey == nil) {
+		return nil, fmt.Errorf("either client secret or private key is required: %w", ErrInvalidClientCredentials)
+	}
+
+	if config.PrivateKey != nil {
+		if _, err := jwtAlgorithm(config.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = newDefaultHTTPClient()
+	}
+
+	if config.RefreshAhead == 0 {
+		config.RefreshAhead = defaultTokenRefreshAhead
+	}
+
+	return &ClientCredentialsTokenSource{config: config}, nil
+}
+
+// Token returns the cached token, or requests the new one in case the cached token is about to expire.
+//
+// Returns ErrUnauthenticated error in case client credentials are rejected.
+// Returns ErrForbidden error in case the client is not allowed the grant or scopes.
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if s.token != nil && now.Add(s.config.RefreshAhead).Before(s.token.ExpiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {clientCredentialsGrantType}}
+
+	if len(s.config.Scopes) != 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+
+	clientID, clientSecret := s.config.ClientID, s.config.ClientSecret
+
+	if s.config.PrivateKey != nil {
+		assertion, err := s.clientAssertion(now)
+		if err != nil {
+			return nil, err
+		}
+
+		form.Set("client_id", clientID)
+		form.Set("client_assertion_type", jwtBearerAssertionType)
+		form.Set("client_assertion", assertion)
+
+		clientID = ""
+	}
+
+	response, err := requestOAuth2Token(ctx, s.config.HTTPClient, s.config.TokenURL, form, clientID, clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("request client credentials token: %w", err)
+	}
+
+	token := &Token{
+		AccessToken: response.AccessToken,
+		TokenType:   response.TokenType,
+		Scope:       response.Scope,
+		ExpiresAt:   response.expiresAt(now),
+	}
+
+	// Tokens of unknown lifetime are not cached, as they cannot be told expired.
+	if token.ExpiresAt.IsZero() {
+		s.token = nil
+	} else {
+		s.token = token
+	}
+
+	return token, nil
+}
+
+// clientAssertion returns JWT authenticating the client on the token endpoint.
+func (s *ClientCredentialsTokenSource) clientAssertion(now time.Time) (string, error) {
+	algorithm, err := jwtAlgorithm(s.config.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err = rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	header := map[string]string{"alg": algorithm, "typ": "JWT"}
+	if s.config.KeyID != "" {
+		header["kid"] = s.config.KeyID
+	}
+
+	claims := map[string]interface{}{
+		"iss": s.config.ClientID,
+		"sub": s.config.ClientID,
+		"aud": s.config.TokenURL,
+		"jti": hex.EncodeToString(jti),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	}
+
+	encodedHeader, err := encodeJWTSegment(header)
+	if err != nil {
+		return "", err
+	}
+
+	encodedClaims, err := encodeJWTSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := signJWT(s.config.PrivateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func jwtAlgorithm(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		if k.Curve.Params().BitSize == 256 {
+			return "ES256", nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported private key %T: %w", key, ErrInvalidClientCredentials)
+}
+
+func signJWT(key crypto.Signer, digest []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+
+		// JWS encodes ECDSA signature as fixed size R || S rather than ASN.1.
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key %T: %w", key, ErrInvalidClientCredentials)
+	}
+}
+
+func encodeJWTSegment(value interface{}) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// WithTokenSource authenticates the service on iamcore and on applications secured by iamcore with access tokens
+// of the source, e.g. ClientCredentialsTokenSource, instead of API key. The API key is optional then.
+func WithTokenSource(source TokenSource) Option {
+	return func(o *Options) {
+		o.tokenSource = source
+	}
+}
+
+// WithTokenSource returns the copy of ServerClient authenticating requests, which carry no credentials,
+// with access tokens of the source.
+func (c *ServerClient) WithTokenSource(source TokenSource) *ServerClient {
+	httpClient := *c.httpClient
+	httpClient.Transport = &serviceCredentialsRoundTripper{
+		base:        httpClient.Transport,
+		credentials: tokenSourceCredentials(source),
+		onlyMissing: true,
+	}
+
+	return NewServerClient(c.serverURL, &httpClient)
+}
+
+// credentialsFunc returns the header authenticating the service.
+type credentialsFunc func(ctx context.Context) (http.Header, error)
+
+func tokenSourceCredentials(source TokenSource) credentialsFunc {
+	return func(ctx context.Context) (http.Header, error) {
+		token, err := source.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return http.Header{authorizationHeaderName: {"Bearer " + token.AccessToken}}, nil
+	}
+}
+
+// serviceCredentialsRoundTripper sets the service credentials on outbound requests.
+type serviceCredentialsRoundTripper struct {
+	base        http.RoundTripper
+	credentials credentialsFunc
+	// fallback returns the previous credentials to retry the request rejected with 401 Unauthorized, if any.
+	fallback func(ctx context.Context) (http.Header, bool)
+	// onlyMissing keeps the credentials the request carries, e.g. the ones of the principal.
+	onlyMissing bool
+}
+
+func (rt *serviceCredentialsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if rt.onlyMissing && carriesCredentials(req.Header) {
+		return base.RoundTrip(req)
+	}
+
+	header, err := rt.credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTripper must not modify the original request.
+	response, err := base.RoundTrip(withCredentials(req.Clone(req.Context()), header))
+	if err != nil || response.StatusCode != http.StatusUnauthorized || rt.fallback == nil {
+		return response, err
+	}
+
+	fallbackHeader, ok := rt.fallback(req.Context())
+	if !ok {
+		return response, nil
+	}
+
+	retry, err := rewindRequest(req)
+	if err != nil {
+		return response, nil
+	}
+
+	response.Body.Close()
+
+	return base.RoundTrip(withCredentials(retry, fallbackHeader))
+}
+
+// withCredentials replaces the credentials of the cloned request.
+func withCredentials(outbound *http.Request, header http.Header) *http.Request {
+	if outbound.Header == nil {
+		outbound.Header = http.Header{}
+	}
+
+	outbound.Header.Del(authorizationHeaderName)
+	outbound.Header.Del(apiKeyHeaderName)
+	delete(outbound.Header, apiKeyHeaderName)
+
+	for name, values := range header {
+		outbound.Header[name] = values
+	}
+
+	return outbound
+}
+
+// carriesCredentials reports whether the header carries credentials. API key header set by SDK as is
+// (see GetAPIKeyAuthorizationHeader) is not in canonical form, so it is looked up in both forms.
+func carriesCredentials(header http.Header) bool {
+	return header.Get(authorizationHeaderName) != "" || header.Get(apiKeyHeaderName) != "" || len(header[apiKeyHeaderName]) != 0
+}
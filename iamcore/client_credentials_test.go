@@ -0,0 +1,164 @@
+package iamcore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCredentialsTokenSourceWithSecret(t *testing.T) {
+	var issued int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != "svc" || secret != "s3cr3t" || r.FormValue("grant_type") != clientCredentialsGrantType {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+
+			return
+		}
+
+		issued++
+
+		// The token expiring within the refresh period is requested again on each use.
+		_, _ = w.Write([]byte(`{"access_token":"token-` + string(rune('0'+issued)) + `","token_type":"Bearer","expires_in":30}`))
+	}))
+	defer server.Close()
+
+	source, err := NewClientCredentialsTokenSource(ClientCredentialsConfig{
+		TokenURL: server.URL, ClientID: "svc", ClientSecret: "s3cr3t", HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := newTestClient(t, WithTokenSource(source))
+
+	var received string
+
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(authorizationHeaderName)
+	}))
+	defer service.Close()
+
+	response, err := (&http.Client{Transport: c.ServiceTransport(nil)}).Get(service.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	response.Body.Close()
+
+	header, err := c.ServiceAuthorizationHeader(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if received != "Bearer token-1" || header.Get(authorizationHeaderName) != "Bearer token-2" {
+		t.Fatalf("expected refreshed tokens, got %q and %q", received, header.Get(authorizationHeaderName))
+	}
+
+	tokenOnly, err := NewClient("", "http://iamcore.invalid", false, WithTokenSource(source))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if header := tokenOnly.GetAPIKeyAuthorizationHeader(); header.Get(authorizationHeaderName) != "Bearer token-3" || len(header) != 1 {
+		t.Fatalf("expected token source header only, got %v", header)
+	}
+
+	rejected, _ := NewClientCredentialsTokenSource(ClientCredentialsConfig{
+		TokenURL: server.URL, ClientID: "svc", ClientSecret: "wrong", HTTPClient: server.Client(),
+	})
+	if _, err = rejected.Token(context.Background()); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestClientCredentialsTokenSourceWithoutExpiry(t *testing.T) {
+	var issued int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	source, err := NewClientCredentialsTokenSource(ClientCredentialsConfig{
+		TokenURL: server.URL, ClientID: "svc", ClientSecret: "s3cr3t", HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = source.Token(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if issued != 2 {
+		t.Fatalf("expected token of unknown lifetime requested on each use, got %d requests", issued)
+	}
+}
+
+func TestClientCredentialsTokenSourceWithPrivateKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		parts := strings.Split(r.FormValue("client_assertion"), ".")
+		if r.FormValue("client_assertion_type") != jwtBearerAssertionType || len(parts) != 3 {
+			t.Errorf("unexpected assertion request %v", r.Form)
+
+			return
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+		if len(signature) != 64 || !ecdsa.Verify(&key.PublicKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+			t.Errorf("invalid client assertion signature")
+		}
+
+		claims, _ := decodeJWTClaims(r.FormValue("client_assertion"))
+		if claims["sub"] != "svc" || claims["aud"] != "http://"+r.Host+r.URL.Path {
+			t.Errorf("unexpected client assertion claims %v", claims)
+		}
+
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	source, err := NewClientCredentialsTokenSource(ClientCredentialsConfig{
+		TokenURL: server.URL + OAuth2TokenPath, ClientID: "svc", PrivateKey: key, HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = source.Token(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected cached token, got %d token requests", requests)
+	}
+}
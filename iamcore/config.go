@@ -0,0 +1,353 @@
+package iamcore
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileEnvKey = "IAMCORE_CONFIG"
+	apiKeyFileEnvKey = "IAMCORE_API_KEY_FILE" //#nosec
+	disabledEnvKey   = "IAMCORE_DISABLED"
+	timeoutEnvKey    = "IAMCORE_TIMEOUT"
+	cacheTTLEnvKey   = "IAMCORE_CACHE_TTL"
+	retryMaxEnvKey   = "IAMCORE_RETRY_MAX"
+	caFileEnvKey     = "IAMCORE_CA_FILE"
+	redactedSecret   = "[REDACTED]"
+)
+
+var ErrInvalidConfig = errors.New("invalid iamcore SDK configuration")
+
+// Config is the iamcore SDK configuration loaded by LoadConfig. Zero fields fall back to SDK defaults.
+type Config struct {
+	// ServerURL is iamcore server URL; IAMCORE_URL environment variable.
+	ServerURL string `yaml:"serverURL" json:"serverURL"`
+	// APIKey authenticates the service on iamcore; IAMCORE_API_KEY environment variable.
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+	// APIKeyFile is the file the API key is read from and reloaded on rotation; IAMCORE_API_KEY_FILE environment variable.
+	// It takes precedence over APIKey of the same source; the environment variables override both of the configuration file.
+	APIKeyFile string `yaml:"apiKeyFile" json:"apiKeyFile"`
+	// Disabled disables the SDK, see NewClient; IAMCORE_DISABLED environment variable.
+	Disabled bool `yaml:"disabled" json:"disabled"`
+	// Timeout of requests to iamcore, e.g. "10s"; IAMCORE_TIMEOUT environment variable.
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// CacheTTL is the lifetime of client-side caches, e.g. LocalEvaluator policies; IAMCORE_CACHE_TTL environment variable.
+	// It is not applied by NewClientFromConfig, as the client has no caches; see LocalEvaluatorOptions.
+	CacheTTL time.Duration `yaml:"cacheTTL" json:"cacheTTL"`
+	// RetryMax is the number of retries of requests failed on stale connections; IAMCORE_RETRY_MAX environment variable.
+	RetryMax *int `yaml:"retryMax" json:"retryMax"`
+	// CAFile is PEM encoded root CAs to verify iamcore server certificate; IAMCORE_CA_FILE environment variable.
+	CAFile string `yaml:"caFile" json:"caFile"`
+}
+
+// LoadConfig loads the configuration from YAML or JSON file, if the path or IAMCORE_CONFIG environment variable is set,
+// and overrides it with IAMCORE_* environment variables. Options passed to NewClientFromConfig take precedence over both.
+//
+// Returns ErrInvalidConfig error in case the file has unknown fields, or values are malformed.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(configFileEnvKey)
+	}
+
+	config := &Config{}
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open iamcore config: %w", err)
+		}
+
+		defer file.Close()
+
+		if config, err = DecodeConfig(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// DecodeConfig decodes the configuration from YAML or JSON, which is a subset of YAML.
+//
+// Returns ErrInvalidConfig error in case the document is malformed or has unknown fields.
+func DecodeConfig(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	config := &Config{}
+
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidConfig)
+	}
+
+	return config, nil
+}
+
+// applyEnv overrides the configuration with the environment variables set.
+func (c *Config) applyEnv() error {
+	if value := os.Getenv(iamcoreURLEnvKey); value != "" {
+		c.ServerURL = value
+	}
+
+	// Either API key environment variable overrides both API key and API key file of the configuration file.
+	if apiKey, apiKeyFile := os.Getenv(apiKeyEnvKey), os.Getenv(apiKeyFileEnvKey); apiKey != "" || apiKeyFile != "" {
+		c.APIKey, c.APIKeyFile = apiKey, apiKeyFile
+	}
+
+	if value := os.Getenv(caFileEnvKey); value != "" {
+		c.CAFile = value
+	}
+
+	if value := os.Getenv(disabledEnvKey); value != "" {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v: %w", disabledEnvKey, err, ErrInvalidConfig)
+		}
+
+		c.Disabled = disabled
+	}
+
+	for name, duration := range map[string]*time.Duration{timeoutEnvKey: &c.Timeout, cacheTTLEnvKey: &c.CacheTTL} {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: %v: %w", name, err, ErrInvalidConfig)
+			}
+
+			*duration = parsed
+		}
+	}
+
+	if value := os.Getenv(retryMaxEnvKey); value != "" {
+		retryMax, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v: %w", retryMaxEnvKey, err, ErrInvalidConfig)
+		}
+
+		c.RetryMax = &retryMax
+	}
+
+	return nil
+}
+
+// Validate checks the configuration values. Missing API key is not checked, as it may be supplied by options.
+//
+// Returns ErrInvalidServerURL error in case the server URL is not absolute HTTP(S) URL.
+// Returns ErrInvalidConfig error in case durations or retries are negative.
+func (c *Config) Validate() error {
+	if c.ServerURL != "" {
+		if _, err := validateServerURL(c.ServerURL); err != nil {
+			return err
+		}
+	}
+
+	if c.Timeout < 0 || c.CacheTTL < 0 {
+		return fmt.Errorf("timeout and cache TTL must not be negative: %w", ErrInvalidConfig)
+	}
+
+	if c.RetryMax != nil && *c.RetryMax < 0 {
+		return fmt.Errorf("retry max must not be negative: %w", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Options returns the options applying the configuration, to be passed to NewClient before explicit options.
+//
+// Returns ErrInvalidConfig error in case CA or API key file cannot be read.
+func (c *Config) Options() ([]Option, error) {
+	var opts []Option
+
+	if c.Timeout != 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+
+	if c.RetryMax != nil {
+		opts = append(opts, WithMaxRetries(*c.RetryMax))
+	}
+
+	rootCAs, err := c.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	if rootCAs != nil {
+		opts = append(opts, WithRootCAs(rootCAs))
+	}
+
+	switch {
+	case c.APIKeyFile != "":
+		credentials, err := NewFileCredentials(c.APIKeyFile, FileCredentialsOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidConfig)
+		}
+
+		opts = append(opts, WithCredentialProvider(credentials))
+	case c.APIKey != "":
+		opts = append(opts, WithCredentialProvider(StaticCredentials(c.APIKey)))
+	}
+
+	return opts, nil
+}
+
+// HTTPClient returns the HTTP client of the configured timeout, retries and root CAs, as used by the client created
+// by NewClientFromConfig. See ClientCredentialsConfig, TokenExchangeOptions and StreamEventSourceOptions.
+//
+// Returns ErrInvalidConfig error in case CA file cannot be read.
+func (c *Config) HTTPClient() (*http.Client, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = requestTimeout
+	}
+
+	return c.newHTTPClient(timeout)
+}
+
+// ClientCredentialsConfig returns ClientCredentialsTokenSource configuration calling iamcore token endpoint by the configured
+// HTTP client, see HTTPClient. The client credentials are to be set by the caller.
+//
+// Returns ErrInvalidConfig error in case CA file cannot be read.
+func (c *Config) ClientCredentialsConfig() (ClientCredentialsConfig, error) {
+	httpClient, err := c.HTTPClient()
+	if err != nil {
+		return ClientCredentialsConfig{}, err
+	}
+
+	return ClientCredentialsConfig{TokenURL: c.tokenURL(), HTTPClient: httpClient}, nil
+}
+
+// TokenExchangeOptions returns TokenExchanger options calling the token endpoint by the configured HTTP client, see HTTPClient.
+//
+// Returns ErrInvalidConfig error in case CA file cannot be read.
+func (c *Config) TokenExchangeOptions() (TokenExchangeOptions, error) {
+	httpClient, err := c.HTTPClient()
+	if err != nil {
+		return TokenExchangeOptions{}, err
+	}
+
+	return TokenExchangeOptions{HTTPClient: httpClient}, nil
+}
+
+// StreamEventSourceOptions returns StreamEventSource options opening the stream by the HTTP client of the configured
+// retries and root CAs. The timeout is not applied, as it would limit the stream duration.
+//
+// Returns ErrInvalidConfig error in case CA file cannot be read.
+func (c *Config) StreamEventSourceOptions() (StreamEventSourceOptions, error) {
+	httpClient, err := c.newHTTPClient(0)
+	if err != nil {
+		return StreamEventSourceOptions{}, err
+	}
+
+	return StreamEventSourceOptions{HTTPClient: httpClient}, nil
+}
+
+func (c *Config) newHTTPClient(timeout time.Duration) (*http.Client, error) {
+	rootCAs, err := c.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	maxRetries := maxConnRetries
+	if c.RetryMax != nil {
+		maxRetries = *c.RetryMax
+	}
+
+	return newHTTPClient(timeout, maxRetries, rootCAs), nil
+}
+
+// rootCAs reads the root CAs of CA file; nil if CA file is not configured.
+func (c *Config) rootCAs() (*x509.CertPool, error) {
+	if c.CAFile == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %v: %w", err, ErrInvalidConfig)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in CA file %s: %w", c.CAFile, ErrInvalidConfig)
+	}
+
+	return rootCAs, nil
+}
+
+// tokenURL returns iamcore token endpoint of the configured server URL, or of the default one.
+func (c *Config) tokenURL() string {
+	serverURL := c.ServerURL
+	if serverURL == "" {
+		serverURL = iamcoreDefaultURL
+	}
+
+	return strings.TrimRight(serverURL, "/") + OAuth2TokenPath
+}
+
+// LocalEvaluatorOptions returns LocalEvaluator options refreshing policies once the cache TTL elapses.
+func (c *Config) LocalEvaluatorOptions() LocalEvaluatorOptions {
+	return LocalEvaluatorOptions{RefreshInterval: c.CacheTTL}
+}
+
+// String returns the configuration with secrets redacted, so it is safe to log.
+func (c Config) String() string {
+	apiKey := ""
+	if c.APIKey != "" {
+		apiKey = redactedSecret
+	}
+
+	// The server URL may carry credentials as user info.
+	serverURL := c.ServerURL
+	if parsed, err := url.Parse(serverURL); err == nil && parsed.User != nil {
+		serverURL = parsed.Redacted()
+	}
+
+	retryMax := "default"
+	if c.RetryMax != nil {
+		retryMax = strconv.Itoa(*c.RetryMax)
+	}
+
+	return fmt.Sprintf("{ServerURL:%s APIKey:%s APIKeyFile:%s Disabled:%t Timeout:%s CacheTTL:%s RetryMax:%s CAFile:%s}",
+		serverURL, apiKey, c.APIKeyFile, c.Disabled, c.Timeout, c.CacheTTL, retryMax, c.CAFile)
+}
+
+// GoString redacts secrets of the configuration printed with %#v.
+func (c Config) GoString() string {
+	return "iamcore.Config" + c.String()
+}
+
+// NewClientFromConfig creates Client of the configuration. The options take precedence over the configuration.
+//
+// Returns ErrInvalidConfig error in case CA or API key file cannot be read.
+// Returns ErrEmptyAPIKey error in case no API key or token source is configured.
+// Returns ErrInvalidServerURL error in case the server URL is not absolute HTTP(S) URL.
+func NewClientFromConfig(config *Config, opts ...Option) (Client, error) {
+	if config.Disabled {
+		return NewClient("", "", true, opts...)
+	}
+
+	configOpts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient("", config.ServerURL, false, append(configOpts, opts...)...)
+}
@@ -0,0 +1,106 @@
+package iamcore
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+const (
+	cookieAuthenticatorName = "cookie"
+
+	defaultCSRFHeaderName = "X-CSRF-Token"
+)
+
+// CookieAuthenticator authenticates requests by means of OAuth 2.0 Access Token stored in a cookie,
+// as browser-facing applications usually keep it in an HttpOnly cookie.
+// The principal authorization header is "Authorization: Bearer <access-token>", so it can be propagated
+// downstream the same way as for Bearer.
+type CookieAuthenticator struct {
+	iamcore    *ServerClient
+	cookieName string
+
+	csrfCookieName string
+	csrfHeaderName string
+}
+
+// CookieAuthenticatorOption configures CookieAuthenticator.
+type CookieAuthenticatorOption func(*CookieAuthenticator)
+
+// WithCSRFDoubleSubmit enables CSRF double-submit token validation: requests with unsafe HTTP methods must carry
+// the value of csrfCookieName cookie in csrfHeaderName header; "X-CSRF-Token" header is used if csrfHeaderName is empty.
+func WithCSRFDoubleSubmit(csrfCookieName, csrfHeaderName string) CookieAuthenticatorOption {
+	return func(a *CookieAuthenticator) {
+		if csrfHeaderName == "" {
+			csrfHeaderName = defaultCSRFHeaderName
+		}
+
+		a.csrfCookieName = csrfCookieName
+		a.csrfHeaderName = csrfHeaderName
+	}
+}
+
+func NewCookieAuthenticator(iamcore *ServerClient, cookieName string, opts ...CookieAuthenticatorOption) *CookieAuthenticator {
+	authenticator := &CookieAuthenticator{
+		iamcore:    iamcore,
+		cookieName: cookieName,
+	}
+
+	for _, opt := range opts {
+		opt(authenticator)
+	}
+
+	return authenticator
+}
+
+// Authenticate authenticates the request by its header only. As the request method is unknown,
+// CSRF token is always required if CSRF validation is enabled.
+func (a *CookieAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, http.Header, error) {
+	return a.authenticate(ctx, header, "")
+}
+
+func (a *CookieAuthenticator) AuthenticateRequest(r *http.Request) (*Principal, http.Header, error) {
+	return a.authenticate(r.Context(), r.Header, r.Method)
+}
+
+func (a *CookieAuthenticator) authenticate(ctx context.Context, header http.Header, method string) (*Principal, http.Header, error) {
+	request := &http.Request{Header: header}
+
+	accessTokenCookie, err := request.Cookie(a.cookieName)
+	if err != nil || accessTokenCookie.Value == "" {
+		return nil, nil, nil
+	}
+
+	if a.csrfCookieName != "" && !isSafeMethod(method) {
+		if err = a.validateCSRFToken(request); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return authenticateAccessToken(ctx, a.iamcore, accessTokenCookie.Value, cookieAuthenticatorName)
+}
+
+func (a *CookieAuthenticator) validateCSRFToken(request *http.Request) error {
+	csrfCookie, err := request.Cookie(a.csrfCookieName)
+	if err != nil || csrfCookie.Value == "" {
+		return fmt.Errorf("missing CSRF token cookie: %w", ErrUnauthenticated)
+	}
+
+	csrfHeader := request.Header.Get(a.csrfHeaderName)
+	if subtle.ConstantTimeCompare([]byte(csrfHeader), []byte(csrfCookie.Value)) != 1 {
+		return fmt.Errorf("CSRF token mismatch: %w", ErrUnauthenticated)
+	}
+
+	return nil
+}
+
+// isSafeMethod reports whether the HTTP method is safe as defined by RFC 7231.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
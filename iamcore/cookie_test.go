@@ -0,0 +1,72 @@
+package iamcore
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCookieAuthenticatorCSRFDoubleSubmit(t *testing.T) {
+	authenticator := NewCookieAuthenticator(nil, "access_token", WithCSRFDoubleSubmit("csrf_token", ""))
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.AddCookie(&http.Cookie{Name: "access_token", Value: "token"})
+	request.AddCookie(&http.Cookie{Name: "csrf_token", Value: "csrf"})
+	request.Header.Set("X-CSRF-Token", "other")
+
+	if _, _, err := authenticator.AuthenticateRequest(request); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated on CSRF token mismatch, got %v", err)
+	}
+
+	request.Header.Del("X-CSRF-Token")
+
+	if _, _, err := authenticator.AuthenticateRequest(request); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated on missing CSRF header, got %v", err)
+	}
+}
+
+func TestCookieAuthenticatorCSRFDoubleSubmitMatch(t *testing.T) {
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + userIRNPath: {http.StatusOK, `{"data":"irn:rc73dbh7q0:iamcore:acme::user/john"}`},
+	})
+
+	c := newServerTestClient(t, server.Server, WithAuthenticators(func(iamcore *ServerClient) []Authenticator {
+		return []Authenticator{NewCookieAuthenticator(iamcore, "access_token", WithCSRFDoubleSubmit("csrf_token", ""))}
+	}))
+
+	var principal *Principal
+
+	handler := c.WithAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.AddCookie(&http.Cookie{Name: "access_token", Value: "token"})
+	request.AddCookie(&http.Cookie{Name: "csrf_token", Value: "csrf"})
+	request.Header.Set("X-CSRF-Token", "csrf")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	if principal == nil || principal.IRN.GetResourceID() != "john" || principal.Authenticator != cookieAuthenticatorName {
+		t.Fatalf("unexpected principal in context %+v", principal)
+	}
+
+	if header := server.lastRequest(t).header.Get(authorizationHeaderName); header != "Bearer token" {
+		t.Fatalf("expected cookie token resolved on iamcore, got %q", header)
+	}
+}
+
+func TestCookieAuthenticatorNoCookie(t *testing.T) {
+	authenticator := NewCookieAuthenticator(nil, "access_token")
+
+	principal, header, err := authenticator.AuthenticateRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	if principal != nil || header != nil || err != nil {
+		t.Fatalf("expected authenticator to be skipped without cookie, got %v %v %v", principal, header, err)
+	}
+}
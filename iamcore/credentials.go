@@ -0,0 +1,166 @@
+package iamcore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCredentialsPollInterval = 10 * time.Second
+	defaultCredentialsGracePeriod  = 5 * time.Minute
+)
+
+// CredentialProvider resolves the API key on each use, so that the key can be rotated without restart.
+type CredentialProvider interface {
+	// APIKey returns the current API key.
+	APIKey(ctx context.Context) (string, error)
+}
+
+// RotatingCredentialProvider is CredentialProvider keeping the previous API key for a grace period after rotation,
+// so that outbound requests rejected with the new key, e.g. not yet propagated to the receiver, are retried with the old one.
+type RotatingCredentialProvider interface {
+	CredentialProvider
+	// PreviousAPIKey returns the API key replaced by the current one, if the rotation happened within the grace period.
+	PreviousAPIKey(ctx context.Context) (string, bool)
+}
+
+// WithCredentialProvider resolves the API key by means of the provider instead of the key passed to NewClient.
+func WithCredentialProvider(provider CredentialProvider) Option {
+	return func(o *Options) {
+		o.credentials = provider
+	}
+}
+
+// StaticCredentials is CredentialProvider of the fixed API key.
+type StaticCredentials string
+
+func (s StaticCredentials) APIKey(context.Context) (string, error) {
+	if s == "" {
+		return "", ErrEmptyAPIKey
+	}
+
+	return string(s), nil
+}
+
+// EnvCredentials returns CredentialProvider reading the API key from the environment variable on each use.
+func EnvCredentials(name string) CredentialProvider {
+	return CredentialsFunc(func(context.Context) (string, error) {
+		apiKey := os.Getenv(name)
+		if apiKey == "" {
+			return "", fmt.Errorf("environment variable %s: %w", name, ErrEmptyAPIKey)
+		}
+
+		return apiKey, nil
+	})
+}
+
+// CredentialsFunc is CredentialProvider calling the function, e.g. to fetch the API key from a secrets manager.
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// FileCredentialsOptions configures FileCredentials.
+type FileCredentialsOptions struct {
+	// PollInterval is the minimum interval between file reads; 10 seconds by default.
+	PollInterval time.Duration
+	// GracePeriod is the time the previous API key is kept after the rotation; 5 minutes by default.
+	GracePeriod time.Duration
+}
+
+// FileCredentials is RotatingCredentialProvider reading the API key from the file, e.g. Kubernetes secret mount.
+// The file is not watched; it is re-read on use once the poll interval elapses since the last read, so the changed key
+// takes effect on the first use after the poll interval, and up to the poll interval later than the file changes.
+// In case the file cannot be read, the last read API key remains in effect.
+type FileCredentials struct {
+	path    string
+	options FileCredentialsOptions
+
+	mu        sync.Mutex
+	apiKey    string
+	previous  string
+	rotatedAt time.Time
+	readAt    time.Time
+}
+
+// NewFileCredentials creates FileCredentials and reads the API key from the file.
+//
+// Returns ErrEmptyAPIKey error in case the file is empty.
+func NewFileCredentials(path string, options FileCredentialsOptions) (*FileCredentials, error) {
+	if options.PollInterval == 0 {
+		options.PollInterval = defaultCredentialsPollInterval
+	}
+
+	if options.GracePeriod == 0 {
+		options.GracePeriod = defaultCredentialsGracePeriod
+	}
+
+	apiKey, err := readAPIKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileCredentials{
+		path:    path,
+		options: options,
+		apiKey:  apiKey,
+		readAt:  time.Now(),
+	}, nil
+}
+
+func (f *FileCredentials) APIKey(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(f.readAt) < f.options.PollInterval {
+		return f.apiKey, nil
+	}
+
+	f.readAt = now
+
+	apiKey, err := readAPIKeyFile(f.path)
+	if err != nil {
+		log.Printf("Error reloading iamcore API key, the last read key remains in effect: %v", err)
+
+		return f.apiKey, nil
+	}
+
+	if apiKey != f.apiKey {
+		f.previous, f.apiKey, f.rotatedAt = f.apiKey, apiKey, now
+	}
+
+	return f.apiKey, nil
+}
+
+func (f *FileCredentials) PreviousAPIKey(context.Context) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.previous == "" || time.Since(f.rotatedAt) > f.options.GracePeriod {
+		return "", false
+	}
+
+	return f.previous, true
+}
+
+func readAPIKeyFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read API key file: %w", err)
+	}
+
+	apiKey := string(bytes.TrimSpace(content))
+	if apiKey == "" {
+		return "", fmt.Errorf("API key file %s: %w", path, ErrEmptyAPIKey)
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,90 @@
+package iamcore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileCredentialsRotationGracePeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(path, []byte("old-key\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	credentials, err := NewFileCredentials(path, FileCredentialsOptions{PollInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := newTestClient(t, WithCredentialProvider(credentials))
+
+	if err = os.WriteFile(path, []byte("new-key\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var attempts []string
+
+	// The receiver has not picked up the new key yet.
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		attempts = append(attempts, r.Header.Get(apiKeyHeaderName)+" "+string(body))
+
+		if r.Header.Get(apiKeyHeaderName) != "old-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer service.Close()
+
+	httpClient := &http.Client{Transport: c.ServiceTransport(nil)}
+
+	response, err := httpClient.Post(service.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK || len(attempts) != 2 || attempts[0] != "new-key payload" || attempts[1] != "old-key payload" {
+		t.Fatalf("expected retry with the previous key, got status %d, attempts %v", response.StatusCode, attempts)
+	}
+
+	if apiKey := c.GetAPIKeyAuthorizationHeader()[apiKeyHeaderName][0]; apiKey != "new-key" {
+		t.Fatalf("expected rotated key, got %q", apiKey)
+	}
+}
+
+func TestEnvCredentialsResolvedOnUse(t *testing.T) {
+	defer os.Unsetenv(apiKeyEnvKey)
+
+	_ = os.Setenv(apiKeyEnvKey, "first")
+
+	c, err := NewClient("", "http://iamcore.invalid", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = os.Setenv(apiKeyEnvKey, "second")
+
+	if apiKey := c.GetAPIKeyAuthorizationHeader()[apiKeyHeaderName][0]; apiKey != "second" {
+		t.Fatalf("expected key read on use, got %q", apiKey)
+	}
+
+	_ = os.Unsetenv(apiKeyEnvKey)
+
+	if header := c.GetAPIKeyAuthorizationHeader(); len(header) != 0 {
+		t.Fatalf("expected no header of empty key, got %v", header)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	c.SetAPIKeyAuthorizationHeader(request)
+
+	if len(request.Header) != 0 {
+		t.Fatalf("expected no header of empty key, got %v", request.Header)
+	}
+}
@@ -0,0 +1,242 @@
+package iamcore
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// UserFilter filters users listed by ListUsers. Empty fields are not applied.
+type UserFilter struct {
+	TenantID string
+	Username string
+	Email    string
+	// Page number, starting from 1; the first page by default.
+	Page int
+	// PageSize is the maximum number of users in the page; iamcore default if zero.
+	PageSize int
+}
+
+// APIKeyFilter filters API keys listed by ListAPIKeys. Empty fields are not applied.
+type APIKeyFilter struct {
+	TenantID string
+	// Page number, starting from 1; the first page by default.
+	Page int
+	// PageSize is the maximum number of API keys in the page; iamcore default if zero.
+	PageSize int
+}
+
+// Directory manages iamcore users, groups and API keys.
+type Directory interface {
+	// CreateUser creates user on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated user found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the user.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreateUser(ctx context.Context, authorizationHeader http.Header, user *CreateUserRequestDTO) (*UserResponseDTO, error)
+
+	// GetUser retrieves user from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case user does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the user.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	GetUser(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN) (*UserResponseDTO, error)
+
+	// ListUsers retrieves a page of users matching the filter from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read users.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListUsers(ctx context.Context, authorizationHeader http.Header, filter UserFilter) (*UsersResponseDTO, error)
+
+	// SetUserEnabled enables or disables user on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case user does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the user.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	SetUserEnabled(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN, enabled bool) error
+
+	// CreateGroup creates group on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated group found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the group.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreateGroup(ctx context.Context, authorizationHeader http.Header, group *CreateGroupRequestDTO) (*GroupResponseDTO, error)
+
+	// AddGroupMembers adds users to group on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case group or any of users does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the group.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	AddGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error
+
+	// RemoveGroupMembers removes users from group on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case group does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the group.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	RemoveGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error
+
+	// CreateAPIKey creates API key on iamcore. The returned APIKeyResponseDTO.APIKey secret is not retrievable later.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated API key found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the API key.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreateAPIKey(ctx context.Context, authorizationHeader http.Header, apiKey *CreateAPIKeyRequestDTO) (*APIKeyResponseDTO, error)
+
+	// ListAPIKeys retrieves a page of API keys matching the filter from iamcore. Secrets are not returned.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read API keys.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListAPIKeys(ctx context.Context, authorizationHeader http.Header, filter APIKeyFilter) (*APIKeysResponseDTO, error)
+
+	// RevokeAPIKey revokes API key on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case API key does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to revoke the API key.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	RevokeAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) error
+
+	// RotateAPIKey issues a new secret for API key on iamcore, keeping its policies; the previous secret stops working.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case API key does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to rotate the API key.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	RotateAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) (*APIKeyResponseDTO, error)
+}
+
+func (c *client) CreateUser(ctx context.Context, authorizationHeader http.Header, user *CreateUserRequestDTO) (*UserResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.CreateUser(ctx, authorizationHeader, user)
+}
+
+func (c *client) GetUser(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN) (*UserResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetUser(ctx, authorizationHeader, userIRN)
+}
+
+func (c *client) ListUsers(ctx context.Context, authorizationHeader http.Header, filter UserFilter) (*UsersResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetUsers(ctx, authorizationHeader, filter.query())
+}
+
+func (c *client) SetUserEnabled(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN, enabled bool) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.UpdateUser(ctx, authorizationHeader, userIRN, &UpdateUserRequestDTO{Enabled: &enabled})
+}
+
+func (c *client) CreateGroup(ctx context.Context, authorizationHeader http.Header, group *CreateGroupRequestDTO) (*GroupResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.CreateGroup(ctx, authorizationHeader, group)
+}
+
+func (c *client) AddGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.AddGroupMembers(ctx, authorizationHeader, groupIRN, userIRNs)
+}
+
+func (c *client) RemoveGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.RemoveGroupMembers(ctx, authorizationHeader, groupIRN, userIRNs)
+}
+
+func (c *client) CreateAPIKey(ctx context.Context, authorizationHeader http.Header, apiKey *CreateAPIKeyRequestDTO) (*APIKeyResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.CreateAPIKey(ctx, authorizationHeader, apiKey)
+}
+
+func (c *client) ListAPIKeys(ctx context.Context, authorizationHeader http.Header, filter APIKeyFilter) (*APIKeysResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetAPIKeys(ctx, authorizationHeader, filter.query())
+}
+
+func (c *client) RevokeAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.DeleteAPIKey(ctx, authorizationHeader, apiKeyIRN)
+}
+
+func (c *client) RotateAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) (*APIKeyResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.RotateAPIKey(ctx, authorizationHeader, apiKeyIRN)
+}
+
+func (f *UserFilter) query() url.Values {
+	return listQuery(map[string]string{
+		"tenantID": f.TenantID,
+		"username": f.Username,
+		"email":    f.Email,
+	}, f.Page, f.PageSize)
+}
+
+func (f *APIKeyFilter) query() url.Values {
+	return listQuery(map[string]string{
+		"tenantID": f.TenantID,
+	}, f.Page, f.PageSize)
+}
@@ -0,0 +1,127 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func TestDirectoryUsersAndGroups(t *testing.T) {
+	userIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "user", nil, "john")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groupIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "group", nil, "staff")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	userURLPath := userPath + "/" + userIRN.Base64()
+	groupURLPath := groupPath + "/" + groupIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + userPath:                        {http.StatusConflict, `{"message":"user already exists"}`},
+		"GET " + userPath:                         {http.StatusOK, `{"data":[{"id":"john","username":"john"}],"count":1}`},
+		"GET " + userURLPath:                      {http.StatusOK, `{"data":{"id":"john","username":"john","email":"john@acme.com"}}`},
+		"PATCH " + userURLPath:                    {http.StatusNoContent, ""},
+		"POST " + groupPath:                       {http.StatusCreated, `{"data":{"id":"staff","name":"staff"}}`},
+		"PUT " + groupURLPath + "/members/add":    {http.StatusNoContent, ""},
+		"PUT " + groupURLPath + "/members/remove": {http.StatusNotFound, `{"message":"group not found"}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	if _, err = c.CreateUser(ctx, nil, &CreateUserRequestDTO{Username: "john", Email: "john@acme.com"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict error, got %v", err)
+	}
+
+	user, err := c.GetUser(ctx, nil, userIRN)
+	if err != nil || user.Email != "john@acme.com" {
+		t.Fatalf("unexpected user %+v: %v", user, err)
+	}
+
+	users, err := c.ListUsers(ctx, nil, UserFilter{TenantID: "acme", Email: "john+it@acme.com", PageSize: 20})
+	if err != nil || users.Count != 1 || users.Data[0].Username != "john" {
+		t.Fatalf("unexpected users %+v: %v", users, err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "email=john%2Bit%40acme.com&pageSize=20&tenantID=acme" {
+		t.Fatalf("unexpected list query %s", query)
+	}
+
+	if err = c.SetUserEnabled(ctx, nil, userIRN, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"enabled":false}` {
+		t.Fatalf("unexpected update request %s", body)
+	}
+
+	group, err := c.CreateGroup(ctx, nil, &CreateGroupRequestDTO{Name: "staff", TenantID: "acme"})
+	if err != nil || group.Name != "staff" {
+		t.Fatalf("unexpected group %+v: %v", group, err)
+	}
+
+	if err = c.AddGroupMembers(ctx, nil, groupIRN, []*irn.IRN{userIRN}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"userIDs":["`+userIRN.Base64()+`"]}` {
+		t.Fatalf("unexpected add members request %s", body)
+	}
+
+	if err = c.RemoveGroupMembers(ctx, nil, groupIRN, []*irn.IRN{userIRN}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound error, got %v", err)
+	}
+}
+
+func TestDirectoryAPIKeys(t *testing.T) {
+	apiKeyIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "api-key", nil, "ci")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiKeyURLPath := apiKeyPath + "/" + apiKeyIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + apiKeyPath:                {http.StatusCreated, `{"data":{"id":"ci","name":"ci","apiKey":"s3cr3t"}}`},
+		"GET " + apiKeyPath:                 {http.StatusOK, `{"data":[{"id":"ci","name":"ci"}],"count":1,"page":1}`},
+		"DELETE " + apiKeyURLPath:           {http.StatusForbidden, `{"message":"forbidden"}`},
+		"POST " + apiKeyURLPath + "/rotate": {http.StatusOK, `{"data":{"id":"ci","apiKey":"r0tated"}}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	apiKey, err := c.CreateAPIKey(ctx, nil, &CreateAPIKeyRequestDTO{Name: "ci", TenantID: "acme"})
+	if err != nil || apiKey.APIKey != "s3cr3t" {
+		t.Fatalf("unexpected API key %+v: %v", apiKey, err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"name":"ci","tenantID":"acme"}` {
+		t.Fatalf("unexpected create request %s", body)
+	}
+
+	apiKeys, err := c.ListAPIKeys(ctx, nil, APIKeyFilter{Page: 1})
+	if err != nil || len(apiKeys.Data) != 1 || apiKeys.Data[0].APIKey != "" {
+		t.Fatalf("unexpected API keys %+v: %v", apiKeys, err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "page=1" {
+		t.Fatalf("unexpected list query %s", query)
+	}
+
+	if apiKey, err = c.RotateAPIKey(ctx, nil, apiKeyIRN); err != nil || apiKey.APIKey != "r0tated" {
+		t.Fatalf("unexpected API key %+v: %v", apiKey, err)
+	}
+
+	var apiError *APIError
+	if err = c.RevokeAPIKey(ctx, nil, apiKeyIRN); !errors.Is(err, ErrForbidden) || !errors.As(err, &apiError) || apiError.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected ErrForbidden API error, got %v", err)
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
+// dataResponseDTO is iamcore response wrapping the payload into "data" field.
+type dataResponseDTO struct {
+	Data interface{} `json:"data"`
+}
+
 type PrincipalIRNResponseDTO struct {
 	Data *irn.IRN `json:"data"`
 }
@@ -14,6 +19,14 @@ type ErrorResponseDTO struct {
 	Message string `json:"message"`
 }
 
+type ProblemDetailsDTO struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
 type AuthorizedOnResourceTypeRequestDTO struct {
 	Action       string `json:"action"`
 	ResourceType string `json:"resourceType"`
@@ -40,6 +53,34 @@ type CreateResourceRequestDTO struct {
 	PoolIDs      []string `json:"poolIDs,omitempty"`
 }
 
+type UpdateResourceRequestDTO struct {
+	Name    *string   `json:"name,omitempty"`
+	Path    *string   `json:"path,omitempty"`
+	Enabled *bool     `json:"enabled,omitempty"`
+	PoolIDs *[]string `json:"poolIDs,omitempty"`
+}
+
+type ResourceResponseDTO struct {
+	ID           string    `json:"id"`
+	IRN          *irn.IRN  `json:"irn"`
+	Name         string    `json:"name"`
+	Application  string    `json:"application"`
+	TenantID     string    `json:"tenantID"`
+	Path         string    `json:"path"`
+	ResourceType string    `json:"resourceType"`
+	Enabled      bool      `json:"enabled"`
+	PoolIDs      []string  `json:"poolIDs"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+}
+
+type ResourcesResponseDTO struct {
+	Data     []*ResourceResponseDTO `json:"data"`
+	Count    int                    `json:"count"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"pageSize"`
+}
+
 type CreateResourceTypeRequestDTO struct {
 	Type         string   `json:"type"`
 	Description  string   `json:"description"`
@@ -47,6 +88,12 @@ type CreateResourceTypeRequestDTO struct {
 	Operations   []string `json:"operations"`
 }
 
+type UpdateResourceTypeRequestDTO struct {
+	Description  string   `json:"description"`
+	ActionPrefix string   `json:"actionPrefix"`
+	Operations   []string `json:"operations"`
+}
+
 type ResourceTypeResponseDTO struct {
 	ID           string    `json:"id"`
 	IRN          *irn.IRN  `json:"irn"`
@@ -72,6 +119,173 @@ type AttachPolicyRequestDTO struct {
 	PolicyIDs []string `json:"policyIDs"`
 }
 
+func newAttachPolicyRequestDTO(policyIRNs []*irn.IRN) *AttachPolicyRequestDTO {
+	policyIDs := make([]string, len(policyIRNs))
+	for i := range policyIRNs {
+		policyIDs[i] = policyIRNs[i].Base64()
+	}
+
+	return &AttachPolicyRequestDTO{
+		PolicyIDs: policyIDs,
+	}
+}
+
+type PolicyStatementDTO struct {
+	Effect      PolicyEffect `json:"effect"`
+	Description string       `json:"description,omitempty"`
+	Resources   []string     `json:"resources"`
+	Actions     []string     `json:"actions"`
+}
+
+type CreatePolicyRequestDTO struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	TenantID    string                `json:"tenantID,omitempty"`
+	Statements  []*PolicyStatementDTO `json:"statements"`
+}
+
+type UpdatePolicyRequestDTO struct {
+	Description string                `json:"description"`
+	Statements  []*PolicyStatementDTO `json:"statements"`
+}
+
+type PolicyResponseDTO struct {
+	ID          string                `json:"id"`
+	IRN         *irn.IRN              `json:"irn"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	TenantID    string                `json:"tenantID"`
+	Statements  []*PolicyStatementDTO `json:"statements"`
+	Created     time.Time             `json:"created"`
+	Updated     time.Time             `json:"updated"`
+}
+
+type PoliciesResponseDTO struct {
+	Data     []*PolicyResponseDTO `json:"data"`
+	Count    int                  `json:"count"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
+type CreateUserRequestDTO struct {
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Password  string `json:"password,omitempty"`
+	TenantID  string `json:"tenantID,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+type UpdateUserRequestDTO struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type UserResponseDTO struct {
+	ID        string    `json:"id"`
+	IRN       *irn.IRN  `json:"irn"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	TenantID  string    `json:"tenantID"`
+	Path      string    `json:"path"`
+	Enabled   bool      `json:"enabled"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+type UsersResponseDTO struct {
+	Data     []*UserResponseDTO `json:"data"`
+	Count    int                `json:"count"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"pageSize"`
+}
+
+type CreateGroupRequestDTO struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	TenantID    string `json:"tenantID,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+type GroupResponseDTO struct {
+	ID          string    `json:"id"`
+	IRN         *irn.IRN  `json:"irn"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	TenantID    string    `json:"tenantID"`
+	Path        string    `json:"path"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type GroupMembersRequestDTO struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+func newGroupMembersRequestDTO(userIRNs []*irn.IRN) *GroupMembersRequestDTO {
+	userIDs := make([]string, len(userIRNs))
+	for i := range userIRNs {
+		userIDs[i] = userIRNs[i].Base64()
+	}
+
+	return &GroupMembersRequestDTO{
+		UserIDs: userIDs,
+	}
+}
+
+type CreateAPIKeyRequestDTO struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	TenantID    string     `json:"tenantID,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+type APIKeyResponseDTO struct {
+	ID          string   `json:"id"`
+	IRN         *irn.IRN `json:"irn"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	TenantID    string   `json:"tenantID"`
+	// APIKey is the secret; returned only on API key creation and rotation.
+	APIKey    string     `json:"apiKey,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+}
+
+type APIKeysResponseDTO struct {
+	Data     []*APIKeyResponseDTO `json:"data"`
+	Count    int                  `json:"count"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
+type CreateTenantRequestDTO struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Path        string `json:"path"`
+}
+
+type TenantResponseDTO struct {
+	ID          string    `json:"id"`
+	IRN         *irn.IRN  `json:"irn"`
+	TenantID    string    `json:"tenantID"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Path        string    `json:"path"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type TenantsResponseDTO struct {
+	Data     []*TenantResponseDTO `json:"data"`
+	Count    int                  `json:"count"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
 type AllowedAndDeniedIRNs struct {
 	Allowed []*irn.IRN
 	Denied  []*irn.IRN
@@ -81,6 +295,8 @@ type EvaluateDebugResourcesRequestDTO struct {
 	Application string       `json:"application"`
 	Actions     []string     `json:"actions"`
 	Resources   []*irn.IRN64 `json:"resources"`
+	// Principal to evaluate the policies of; the authenticated principal if not set.
+	Principal *irn.IRN64 `json:"principal,omitempty"`
 }
 
 type DebugEvaluationActionDetail struct {
@@ -102,6 +318,20 @@ type EvaluateDebugResourcesResponseDTO struct {
 	EvaluationTimeMillis int                            `json:"evaluationTimeMillis"`
 }
 
+type CreatePoolRequestDTO struct {
+	Name        string   `json:"name"`
+	TenantID    string   `json:"tenantID,omitempty"`
+	ResourceIDs []string `json:"resourceIDs,omitempty"`
+}
+
+type UpdatePoolRequestDTO struct {
+	Name string `json:"name"`
+}
+
+type PoolResourcesRequestDTO struct {
+	ResourceIDs []string `json:"resourceIDs"`
+}
+
 type PoolResponseDTO struct {
 	ID          string   `json:"id"`
 	IRN         *irn.IRN `json:"irn"`
@@ -3,10 +3,10 @@ package iamcore
 import (
 	"context"
 	"net/http"
-
-	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
 
+const emptyHeaderAuthenticatorName = "empty-header"
+
 type EmptyHeader struct {
 	iamcore *ServerClient
 }
@@ -17,11 +17,15 @@ func NewEmptyHeader(iamcore *ServerClient) *EmptyHeader {
 	}
 }
 
-func (a *EmptyHeader) Authenticate(ctx context.Context, _ http.Header) (*irn.IRN, http.Header, error) {
+func (a *EmptyHeader) Authenticate(ctx context.Context, _ http.Header) (*Principal, http.Header, error) {
 	principalIRN, err := a.iamcore.GetPrincipalIRN(ctx, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return principalIRN, nil, nil
+	return &Principal{
+		IRN:           principalIRN,
+		Kind:          PrincipalKindAnonymous,
+		Authenticator: emptyHeaderAuthenticatorName,
+	}, nil, nil
 }
@@ -0,0 +1,157 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/internal/sets"
+)
+
+// EnsureAction is the action taken by EnsureResourceType and EnsureResource to reach the desired state.
+type EnsureAction string
+
+const (
+	EnsureActionCreated   EnsureAction = "created"
+	EnsureActionUpdated   EnsureAction = "updated"
+	EnsureActionUnchanged EnsureAction = "unchanged"
+)
+
+// EnsureResult describes what EnsureResourceType and EnsureResource changed on iamcore.
+type EnsureResult struct {
+	Action EnsureAction
+	// Changes are human-readable descriptions of the updated fields, e.g. "operations: +export -import".
+	Changes []string
+}
+
+func (c *client) EnsureResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType,
+	actionPrefix string, operations []string,
+) (*EnsureResult, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	applicationIRN, err := irn.NewIRN(accountID, "iamcore", "", nil, "application", nil, application)
+	if err != nil {
+		return nil, err
+	}
+
+	existingTypes, err := c.iamcoreClient.GetResourceTypes(ctx, authorizationHeader, applicationIRN)
+	if err != nil {
+		return nil, err
+	}
+
+	operations = dropEmptyStrings(operations)
+
+	existing := findResourceType(existingTypes, resourceType)
+	if existing == nil {
+		createDTO := &CreateResourceTypeRequestDTO{
+			Type:         resourceType,
+			ActionPrefix: actionPrefix,
+			Operations:   operations,
+		}
+
+		err = c.iamcoreClient.CreateResourceType(ctx, authorizationHeader, applicationIRN, createDTO)
+		if err == nil {
+			return &EnsureResult{Action: EnsureActionCreated}, nil
+		}
+
+		if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+
+		// The resource type has been created concurrently, e.g. by another replica, so reconcile it.
+		if existingTypes, err = c.iamcoreClient.GetResourceTypes(ctx, authorizationHeader, applicationIRN); err != nil {
+			return nil, err
+		}
+
+		if existing = findResourceType(existingTypes, resourceType); existing == nil {
+			return nil, fmt.Errorf("resource type %q conflicts, but is not found: %w", resourceType, ErrConflict)
+		}
+	}
+
+	changes := make([]string, 0)
+
+	if existing.ActionPrefix != actionPrefix {
+		changes = append(changes, fmt.Sprintf("actionPrefix: %q -> %q", existing.ActionPrefix, actionPrefix))
+	}
+
+	if diff := sets.Diff(existing.Operations, operations); diff != "" {
+		changes = append(changes, "operations: "+diff)
+	}
+
+	if len(changes) == 0 {
+		return &EnsureResult{Action: EnsureActionUnchanged}, nil
+	}
+
+	updateDTO := &UpdateResourceTypeRequestDTO{
+		Description:  existing.Description,
+		ActionPrefix: actionPrefix,
+		Operations:   operations,
+	}
+
+	if err = c.iamcoreClient.UpdateResourceType(ctx, authorizationHeader, applicationIRN, existing.IRN, updateDTO); err != nil {
+		return nil, err
+	}
+
+	return &EnsureResult{Action: EnsureActionUpdated, Changes: changes}, nil
+}
+
+func (c *client) EnsureResource(ctx context.Context, authorizationHeader http.Header, accountID, application, tenantID, resourceType,
+	resourcePath, resourceID string, poolIDs []string,
+) (*EnsureResult, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	resourceIRN, err := irn.NewIRN(accountID, application, tenantID, nil, resourceType, irn.SplitPath(resourcePath), resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.iamcoreClient.GetResource(ctx, authorizationHeader, resourceIRN)
+	if errors.Is(err, ErrNotFound) {
+		err = c.CreateResourceWithPools(ctx, authorizationHeader, application, tenantID, resourceType, resourcePath, resourceID, poolIDs)
+		if err == nil {
+			return &EnsureResult{Action: EnsureActionCreated}, nil
+		}
+
+		// The resource has been created concurrently, so reconcile its pools.
+		if errors.Is(err, ErrConflict) {
+			existing, err = c.iamcoreClient.GetResource(ctx, authorizationHeader, resourceIRN)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Pools membership is not managed if desired pools are not specified.
+	diff := ""
+	if poolIDs != nil {
+		diff = sets.Diff(existing.PoolIDs, poolIDs)
+	}
+
+	if diff == "" {
+		return &EnsureResult{Action: EnsureActionUnchanged}, nil
+	}
+
+	if err = c.iamcoreClient.UpdateResource(ctx, authorizationHeader, resourceIRN, &UpdateResourceRequestDTO{PoolIDs: &poolIDs}); err != nil {
+		return nil, err
+	}
+
+	return &EnsureResult{Action: EnsureActionUpdated, Changes: []string{"poolIDs: " + diff}}, nil
+}
+
+func findResourceType(resourceTypes []*ResourceTypeResponseDTO, resourceType string) *ResourceTypeResponseDTO {
+	for _, existing := range resourceTypes {
+		if existing.Type == resourceType {
+			return existing
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnsureResourceType(t *testing.T) {
+	const existingType = `{"irn":"irn:rc73dbh7q0:iamcore:::resource-type/fleet/device","type":"device","actionPrefix":"fleet:device",` +
+		`"operations":["read"]}`
+
+	cases := []struct {
+		name string
+		// listed are the responses to consecutive resource types requests.
+		listed     []string
+		createCode int
+		want       *EnsureResult
+		requests   []string
+	}{
+		{
+			name:       "created",
+			listed:     []string{`[]`},
+			createCode: http.StatusCreated,
+			want:       &EnsureResult{Action: EnsureActionCreated},
+			requests:   []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			name:     "unchanged",
+			listed:   []string{`[` + strings.Replace(existingType, `["read"]`, `["write","read"]`, 1) + `]`},
+			want:     &EnsureResult{Action: EnsureActionUnchanged},
+			requests: []string{http.MethodGet},
+		},
+		{
+			name:     "updated",
+			listed:   []string{`[` + existingType + `]`},
+			want:     &EnsureResult{Action: EnsureActionUpdated, Changes: []string{"operations: +write"}},
+			requests: []string{http.MethodGet, http.MethodPatch},
+		},
+		{
+			name:       "created concurrently",
+			listed:     []string{`[]`, `[` + existingType + `]`},
+			createCode: http.StatusConflict,
+			want:       &EnsureResult{Action: EnsureActionUpdated, Changes: []string{"operations: +write"}},
+			requests:   []string{http.MethodGet, http.MethodPost, http.MethodGet, http.MethodPatch},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method)
+
+				switch r.Method {
+				case http.MethodGet:
+					_, _ = w.Write([]byte(`{"data":` + tc.listed[0] + `}`))
+					tc.listed = tc.listed[1:]
+				case http.MethodPost:
+					w.WriteHeader(tc.createCode)
+					_, _ = w.Write([]byte(`{"message":"resource type already exists"}`))
+				case http.MethodPatch:
+					updateDTO := &UpdateResourceTypeRequestDTO{}
+					if err := json.NewDecoder(r.Body).Decode(updateDTO); err != nil || len(updateDTO.Operations) != 2 {
+						t.Errorf("unexpected update %+v: %v", updateDTO, err)
+					}
+
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+
+			result, err := newServerTestClient(t, server).EnsureResourceType(context.Background(), nil, "rc73dbh7q0", "fleet", "device",
+				"fleet:device", []string{"read", "write", ""})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.want) || !reflect.DeepEqual(requests, tc.requests) {
+				t.Fatalf("got %+v after %v, want %+v after %v", result, requests, tc.want, tc.requests)
+			}
+		})
+	}
+}
+
+func TestEnsureResource(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing string
+		poolIDs  []string
+		want     *EnsureResult
+		requests []string
+	}{
+		{
+			name:     "created",
+			poolIDs:  []string{"p1"},
+			want:     &EnsureResult{Action: EnsureActionCreated},
+			requests: []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			name:     "pools not managed",
+			existing: `{"id":"d1","poolIDs":["p1"]}`,
+			want:     &EnsureResult{Action: EnsureActionUnchanged},
+			requests: []string{http.MethodGet},
+		},
+		{
+			name:     "pools updated",
+			existing: `{"id":"d1","poolIDs":["p1"]}`,
+			poolIDs:  []string{"p2"},
+			want:     &EnsureResult{Action: EnsureActionUpdated, Changes: []string{"poolIDs: +p2 -p1"}},
+			requests: []string{http.MethodGet, http.MethodPatch},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method)
+
+				switch {
+				case r.Method == http.MethodGet && tc.existing == "":
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"message":"resource not found"}`))
+				case r.Method == http.MethodGet:
+					_, _ = w.Write([]byte(`{"data":` + tc.existing + `}`))
+				case r.Method == http.MethodPost:
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+
+			result, err := newServerTestClient(t, server).EnsureResource(context.Background(), nil, "rc73dbh7q0", "fleet", "acme", "device",
+				"/eu", "d1", tc.poolIDs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.want) || !reflect.DeepEqual(requests, tc.requests) {
+				t.Fatalf("got %+v after %v, want %+v after %v", result, requests, tc.want, tc.requests)
+			}
+		})
+	}
+}
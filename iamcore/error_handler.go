@@ -0,0 +1,147 @@
+package iamcore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned in case the request carries no credentials recognized by any of the authenticators.
+	ErrNoCredentials = fmt.Errorf("no credentials: %w", ErrUnauthenticated)
+	// ErrMalformedCredentials is returned in case the request credentials have unexpected format.
+	ErrMalformedCredentials = fmt.Errorf("malformed credentials: %w", ErrUnauthenticated)
+)
+
+const (
+	wwwAuthenticateHeaderName = "WWW-Authenticate"
+	wwwAuthenticateRealm      = "iamcore"
+
+	problemDetailsContentType = "application/problem+json"
+)
+
+// ErrorHandler writes the error response of WithAuth and of application authorization middlewares using HandleError.
+// The status code is derived from the error: 401 Unauthorized for ErrUnauthenticated, 403 Forbidden for ErrForbidden,
+// 400 Bad Request for ErrBadRequest, 404 Not Found for ErrNotFound, 409 Conflict for ErrConflict,
+// the configured upstream error status for iamcore server failures, and 500 Internal Server Error otherwise.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, statusCode int, err error)
+
+// WithErrorHandler replaces the default JSONErrorHandler.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(o *Options) {
+		o.errorHandler = handler
+	}
+}
+
+// WithUpstreamErrorStatus sets the status code of responses to iamcore server failures (5xx responses and transport errors),
+// e.g. 503 Service Unavailable; 500 Internal Server Error by default.
+func WithUpstreamErrorStatus(statusCode int) Option {
+	return func(o *Options) {
+		o.upstreamErrorStatus = statusCode
+	}
+}
+
+// JSONErrorHandler writes {"message": "..."} JSON error response; the default ErrorHandler.
+func JSONErrorHandler(w http.ResponseWriter, _ *http.Request, statusCode int, err error) {
+	setWWWAuthenticateHeader(w, statusCode, err)
+	writeResponseMessage(w, statusCode, publicErrorMessage(statusCode, err))
+}
+
+// ProblemDetailsErrorHandler writes "application/problem+json" error response as defined by RFC 7807.
+func ProblemDetailsErrorHandler(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	setWWWAuthenticateHeader(w, statusCode, err)
+
+	w.Header().Set("Content-Type", problemDetailsContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	w.WriteHeader(statusCode)
+
+	responseDTO := &ProblemDetailsDTO{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   publicErrorMessage(statusCode, err),
+		Instance: r.URL.Path,
+	}
+
+	if err := json.NewEncoder(w).Encode(responseDTO); err != nil {
+		log.Printf("Error writing problem details: %v", err)
+	}
+}
+
+// HandleError writes the error response using the configured ErrorHandler.
+func (c *client) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	c.errorHandler(w, r, c.errorStatusCode(err), err)
+}
+
+func (c *client) errorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case isUpstreamError(err):
+		return c.upstreamErrorStatus
+	case errors.Is(err, ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// isUpstreamError reports whether the error is iamcore server failure: either 5xx response or transport error.
+func isUpstreamError(err error) bool {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlError *url.Error
+
+	return errors.As(err, &urlError)
+}
+
+// publicErrorMessage returns the message safe to expose to the caller: iamcore server responses and
+// server errors are not exposed.
+func publicErrorMessage(statusCode int, err error) string {
+	var apiError *APIError
+	if statusCode >= http.StatusInternalServerError || errors.As(err, &apiError) {
+		return http.StatusText(statusCode)
+	}
+
+	return err.Error()
+}
+
+// setWWWAuthenticateHeader sets "WWW-Authenticate" header of 401 Unauthorized response as defined by RFC 6750.
+func setWWWAuthenticateHeader(w http.ResponseWriter, statusCode int, err error) {
+	if statusCode != http.StatusUnauthorized {
+		return
+	}
+
+	challenge := fmt.Sprintf("Bearer realm=%q", wwwAuthenticateRealm)
+
+	// The error code is omitted in case the request lacks any authentication information.
+	if !errors.Is(err, ErrNoCredentials) {
+		errorCode := "invalid_token"
+		if errors.Is(err, ErrMalformedCredentials) {
+			errorCode = "invalid_request"
+		}
+
+		challenge += fmt.Sprintf(`, error="%s", error_description="%s"`, errorCode, quoteAuthParam(publicErrorMessage(statusCode, err)))
+	}
+
+	w.Header().Set(wwwAuthenticateHeaderName, challenge)
+}
+
+// quoteAuthParam escapes the value to be used in quoted-string of authentication parameter.
+func quoteAuthParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
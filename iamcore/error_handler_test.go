@@ -0,0 +1,86 @@
+package iamcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleErrorHidesUpstreamMessage(t *testing.T) {
+	c := newTestClient(t, WithUpstreamErrorStatus(http.StatusServiceUnavailable), WithErrorHandler(ProblemDetailsErrorHandler))
+
+	recorder := httptest.NewRecorder()
+	upstreamErr := &APIError{StatusCode: http.StatusBadGateway, Message: "upstream connect error", err: ErrUnknown}
+
+	c.HandleError(recorder, httptest.NewRequest(http.MethodGet, "/devices", nil), upstreamErr)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != problemDetailsContentType {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	problem := &ProblemDetailsDTO{}
+	if err := json.NewDecoder(recorder.Body).Decode(problem); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if problem.Status != http.StatusServiceUnavailable || strings.Contains(problem.Detail, "upstream connect error") {
+		t.Fatalf("unexpected problem details: %+v", problem)
+	}
+}
+
+func TestHandleErrorWWWAuthenticate(t *testing.T) {
+	c := newTestClient(t)
+
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"no credentials", ErrNoCredentials, `Bearer realm="iamcore"`},
+		{"malformed", fmt.Errorf("bad header: %w", ErrMalformedCredentials), `Bearer realm="iamcore", error="invalid_request"`},
+		{"invalid token", &APIError{StatusCode: http.StatusUnauthorized, Message: "token expired", err: ErrUnauthenticated},
+			`Bearer realm="iamcore", error="invalid_token", error_description="Unauthorized"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c.HandleError(recorder, httptest.NewRequest(http.MethodGet, "/", nil), tc.err)
+
+			if recorder.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", recorder.Code)
+			}
+			if got := recorder.Header().Get(wwwAuthenticateHeaderName); !strings.HasPrefix(got, tc.want) {
+				t.Fatalf("unexpected WWW-Authenticate header %q, want prefix %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleErrorStatusCode(t *testing.T) {
+	c := newTestClient(t)
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("invalid resource ID: %w", ErrBadRequest), http.StatusBadRequest},
+		{&APIError{StatusCode: http.StatusBadRequest, Message: "invalid IRN", err: ErrBadRequest}, http.StatusBadRequest},
+		{&APIError{StatusCode: http.StatusNotFound, Message: "resource not found", err: ErrNotFound}, http.StatusNotFound},
+		{&APIError{StatusCode: http.StatusConflict, Message: "already exists", err: ErrConflict}, http.StatusConflict},
+		{&APIError{StatusCode: http.StatusForbidden, Message: "forbidden", err: ErrForbidden}, http.StatusForbidden},
+		{fmt.Errorf("unexpected"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		recorder := httptest.NewRecorder()
+		c.HandleError(recorder, httptest.NewRequest(http.MethodGet, "/", nil), tc.err)
+
+		if recorder.Code != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.err, tc.want, recorder.Code)
+		}
+	}
+}
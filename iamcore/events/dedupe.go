@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ReserveStatus is the result of DeduplicationStore.Reserve.
+type ReserveStatus int
+
+const (
+	// Reserved is the status of the event reserved for processing.
+	Reserved ReserveStatus = iota
+	// AlreadyProcessed is the status of the processed event.
+	AlreadyProcessed
+	// InProgress is the status of the event being processed by concurrent delivery.
+	InProgress
+)
+
+// DeduplicationStore tracks the processed event IDs, so that redelivered events are handled once.
+// The in-memory store deduplicates within a single process; shared stores (e.g. Redis) are needed across replicas.
+type DeduplicationStore interface {
+	// Reserve marks the event as being processed, unless it is processed or being processed already.
+	Reserve(ctx context.Context, eventID string) (ReserveStatus, error)
+	// Complete marks the reserved event as processed.
+	Complete(ctx context.Context, eventID string) error
+	// Release drops the reservation of the event failed to process, so that its redelivery is handled.
+	Release(ctx context.Context, eventID string) error
+}
+
+// MemoryDeduplicationStore is in-memory DeduplicationStore remembering processed events for the retention period.
+type MemoryDeduplicationStore struct {
+	retention time.Duration
+
+	mu        sync.Mutex
+	reserved  map[string]struct{}
+	processed map[string]time.Time
+}
+
+// NewMemoryDeduplicationStore creates MemoryDeduplicationStore; the retention should exceed the delivery replay window,
+// as older deliveries are rejected anyway.
+func NewMemoryDeduplicationStore(retention time.Duration) *MemoryDeduplicationStore {
+	return &MemoryDeduplicationStore{
+		retention: retention,
+		reserved:  make(map[string]struct{}),
+		processed: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryDeduplicationStore) Reserve(_ context.Context, eventID string) (ReserveStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for id, expires := range s.processed {
+		if now.After(expires) {
+			delete(s.processed, id)
+		}
+	}
+
+	if _, ok := s.processed[eventID]; ok {
+		return AlreadyProcessed, nil
+	}
+
+	if _, ok := s.reserved[eventID]; ok {
+		return InProgress, nil
+	}
+
+	s.reserved[eventID] = struct{}{}
+
+	return Reserved, nil
+}
+
+func (s *MemoryDeduplicationStore) Complete(_ context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reserved, eventID)
+	s.processed[eventID] = time.Now().Add(s.retention)
+
+	return nil
+}
+
+func (s *MemoryDeduplicationStore) Release(_ context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reserved, eventID)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package events receives iamcore events delivered by webhook: it verifies the delivery signature,
+// drops replayed and duplicated deliveries, and dispatches the events to typed handlers.
+//
+// Experimental: the event types, the envelope and the signature headers are not part of the published
+// iamcore API yet, so they may change in incompatible ways.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// Event types.
+const (
+	TypeUserCreated     = "user.created"
+	TypeUserDeleted     = "user.deleted"
+	TypeAPIKeyRevoked   = "api-key.revoked"
+	TypePolicyUpdated   = "policy.updated"
+	TypeResourceDeleted = "resource.deleted"
+)
+
+// Event is the envelope of iamcore event; Data is decoded into the type specific struct by typed handlers.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// UserCreated is the data of TypeUserCreated event.
+type UserCreated struct {
+	User     *irn.IRN `json:"user"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+}
+
+// UserDeleted is the data of TypeUserDeleted event.
+type UserDeleted struct {
+	User *irn.IRN `json:"user"`
+}
+
+// APIKeyRevoked is the data of TypeAPIKeyRevoked event.
+type APIKeyRevoked struct {
+	APIKey    *irn.IRN `json:"apiKey"`
+	Principal *irn.IRN `json:"principal"`
+}
+
+// PolicyUpdated is the data of TypePolicyUpdated event.
+type PolicyUpdated struct {
+	Policy *irn.IRN `json:"policy"`
+}
+
+// ResourceDeleted is the data of TypeResourceDeleted event.
+type ResourceDeleted struct {
+	Resource *irn.IRN `json:"resource"`
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const maxBodySize = 1 << 20
+
+// ErrMalformedEvent is returned in case the event cannot be decoded. Such deliveries are not retried by iamcore.
+var ErrMalformedEvent = errors.New("malformed event")
+
+// HandlerFunc handles the event. Returned error fails the delivery, so that iamcore retries it later,
+// unless the error wraps ErrMalformedEvent.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handler is http.Handler receiving iamcore webhook deliveries. It responds with:
+//   - 204 No Content in case the event is handled, or there is no handler registered for its type;
+//   - 200 OK in case the event was already handled;
+//   - 400 Bad Request in case the event is malformed;
+//   - 401 Unauthorized in case of invalid signature or stale delivery;
+//   - 409 Conflict in case the event is being handled by concurrent delivery;
+//   - 413 Request Entity Too Large in case the body exceeds 1 MiB;
+//   - 500 Internal Server Error in case the handler fails, and 503 Service Unavailable in case deduplication store fails.
+//
+// iamcore retries deliveries failed with 409 and 5xx status codes.
+type Handler struct {
+	verifier *Verifier
+	store    DeduplicationStore
+	handlers map[string]HandlerFunc
+}
+
+// HandlerOption configures Handler.
+type HandlerOption func(h *Handler)
+
+// WithReplayWindow overrides DefaultReplayWindow.
+func WithReplayWindow(window time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.verifier.ReplayWindow = window
+	}
+}
+
+// WithDeduplicationStore replaces in-memory store of handled event IDs, e.g. with a store shared across replicas.
+func WithDeduplicationStore(store DeduplicationStore) HandlerOption {
+	return func(h *Handler) {
+		h.store = store
+	}
+}
+
+// NewHandler creates Handler of deliveries signed with the webhook secret.
+func NewHandler(secret string, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		verifier: NewVerifier(secret),
+		handlers: make(map[string]HandlerFunc),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.store == nil {
+		h.store = NewMemoryDeduplicationStore(2 * h.verifier.ReplayWindow)
+	}
+
+	return h
+}
+
+// Handle registers the handler of events of the type, replacing the previously registered one.
+func (h *Handler) Handle(eventType string, handler HandlerFunc) {
+	h.handlers[eventType] = handler
+}
+
+// OnUserCreated registers the handler of TypeUserCreated events.
+func (h *Handler) OnUserCreated(handle func(ctx context.Context, event *Event, data *UserCreated) error) {
+	h.Handle(TypeUserCreated, func(ctx context.Context, event *Event) error {
+		data := &UserCreated{}
+		if err := decodeData(event, data); err != nil {
+			return err
+		}
+
+		return handle(ctx, event, data)
+	})
+}
+
+// OnUserDeleted registers the handler of TypeUserDeleted events.
+func (h *Handler) OnUserDeleted(handle func(ctx context.Context, event *Event, data *UserDeleted) error) {
+	h.Handle(TypeUserDeleted, func(ctx context.Context, event *Event) error {
+		data := &UserDeleted{}
+		if err := decodeData(event, data); err != nil {
+			return err
+		}
+
+		return handle(ctx, event, data)
+	})
+}
+
+// OnAPIKeyRevoked registers the handler of TypeAPIKeyRevoked events.
+func (h *Handler) OnAPIKeyRevoked(handle func(ctx context.Context, event *Event, data *APIKeyRevoked) error) {
+	h.Handle(TypeAPIKeyRevoked, func(ctx context.Context, event *Event) error {
+		data := &APIKeyRevoked{}
+		if err := decodeData(event, data); err != nil {
+			return err
+		}
+
+		return handle(ctx, event, data)
+	})
+}
+
+// OnPolicyUpdated registers the handler of TypePolicyUpdated events.
+func (h *Handler) OnPolicyUpdated(handle func(ctx context.Context, event *Event, data *PolicyUpdated) error) {
+	h.Handle(TypePolicyUpdated, func(ctx context.Context, event *Event) error {
+		data := &PolicyUpdated{}
+		if err := decodeData(event, data); err != nil {
+			return err
+		}
+
+		return handle(ctx, event, data)
+	})
+}
+
+// OnResourceDeleted registers the handler of TypeResourceDeleted events.
+func (h *Handler) OnResourceDeleted(handle func(ctx context.Context, event *Event, data *ResourceDeleted) error) {
+	h.Handle(TypeResourceDeleted, func(ctx context.Context, event *Event) error {
+		data := &ResourceDeleted{}
+		if err := decodeData(event, data); err != nil {
+			return err
+		}
+
+		return handle(ctx, event, data)
+	})
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMessage(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+
+		return
+	}
+
+	// The byte over the limit tells the oversized body from the one of exactly the limit size.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, "failed to read request body")
+
+		return
+	}
+
+	if len(body) > maxBodySize {
+		writeMessage(w, http.StatusRequestEntityTooLarge, "request body is too large")
+
+		return
+	}
+
+	if err = h.verifier.Verify(r.Header, body); err != nil {
+		writeMessage(w, http.StatusUnauthorized, err.Error())
+
+		return
+	}
+
+	event := &Event{}
+	if err = json.Unmarshal(body, event); err != nil || event.ID == "" || event.Type == "" {
+		writeMessage(w, http.StatusBadRequest, ErrMalformedEvent.Error())
+
+		return
+	}
+
+	handler, ok := h.handlers[event.Type]
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	h.dispatch(r.Context(), w, event, handler)
+}
+
+func (h *Handler) dispatch(ctx context.Context, w http.ResponseWriter, event *Event, handler HandlerFunc) {
+	status, err := h.store.Reserve(ctx, event.ID)
+	if err != nil {
+		log.Printf("Error reserving iamcore event %s: %v", event.ID, err)
+		writeMessage(w, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+
+		return
+	}
+
+	switch status {
+	case AlreadyProcessed:
+		writeMessage(w, http.StatusOK, "event is already handled")
+
+		return
+	case InProgress:
+		writeMessage(w, http.StatusConflict, "event is being handled by concurrent delivery")
+
+		return
+	}
+
+	if err = handler(ctx, event); err != nil {
+		if releaseErr := h.store.Release(ctx, event.ID); releaseErr != nil {
+			log.Printf("Error releasing iamcore event %s: %v", event.ID, releaseErr)
+		}
+
+		if errors.Is(err, ErrMalformedEvent) {
+			writeMessage(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		log.Printf("Error handling iamcore event %s of type %s: %v", event.ID, event.Type, err)
+		writeMessage(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+
+		return
+	}
+
+	if err = h.store.Complete(ctx, event.ID); err != nil {
+		log.Printf("Error completing iamcore event %s: %v", event.ID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeData(event *Event, data interface{}) error {
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		return fmt.Errorf("%s event %s data: %v: %w", event.Type, event.ID, err, ErrMalformedEvent)
+	}
+
+	return nil
+}
+
+func writeMessage(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		log.Printf("Error writing response message: %v", err)
+	}
+}
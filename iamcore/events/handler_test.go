@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func deliver(h *Handler, verifier *Verifier, body string) int {
+	request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	verifier.Sign(request.Header, []byte(body))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func TestHandlerDispatchesOnce(t *testing.T) {
+	h := NewHandler("secret")
+	verifier := NewVerifier("secret")
+
+	var revoked []string
+
+	failures := 1
+
+	h.OnAPIKeyRevoked(func(_ context.Context, event *Event, data *APIKeyRevoked) error {
+		if failures > 0 {
+			failures--
+
+			return errors.New("temporary failure")
+		}
+
+		revoked = append(revoked, event.ID)
+
+		return nil
+	})
+
+	body := `{"id":"e1","type":"api-key.revoked","data":{"apiKey":"irn:rc73dbh7q0:iamcore:acme:api-key/k1"}}`
+
+	for i, want := range []int{http.StatusInternalServerError, http.StatusNoContent, http.StatusOK} {
+		if code := deliver(h, verifier, body); code != want {
+			t.Fatalf("delivery %d: got status %d, want %d", i, code, want)
+		}
+	}
+
+	if len(revoked) != 1 {
+		t.Fatalf("expected event handled once, got %v", revoked)
+	}
+
+	if code := deliver(h, verifier, `{"id":"e2","type":"api-key.revoked","data":"not an object"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 Bad Request for malformed data, got %d", code)
+	}
+
+	if code := deliver(h, verifier, `{"id":"e3","type":"unknown.type"}`); code != http.StatusNoContent {
+		t.Fatalf("expected 204 No Content for unhandled type, got %d", code)
+	}
+}
+
+func TestHandlerRejectsInvalidDeliveries(t *testing.T) {
+	h := NewHandler("secret")
+	body := `{"id":"e1","type":"user.created","data":{}}`
+
+	if code := deliver(h, NewVerifier("other"), body); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 Unauthorized for invalid signature, got %d", code)
+	}
+
+	stale := NewVerifier("secret")
+	stale.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	if code := deliver(h, stale, body); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 Unauthorized for stale delivery, got %d", code)
+	}
+
+	oversized := `{"id":"e1","type":"user.created","data":{},"padding":"` + strings.Repeat("x", maxBodySize) + `"}`
+
+	if code := deliver(h, NewVerifier("secret"), oversized); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 Request Entity Too Large for oversized delivery, got %d", code)
+	}
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeaderName carries "sha256=<hex>" HMAC-SHA256 signature of "<timestamp>.<body>".
+	SignatureHeaderName = "X-iamcore-Signature"
+	// TimestampHeaderName carries the delivery time as Unix seconds.
+	TimestampHeaderName = "X-iamcore-Timestamp"
+
+	// DefaultReplayWindow is the maximum accepted age of the delivery.
+	DefaultReplayWindow = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleDelivery    = errors.New("webhook delivery timestamp is out of replay window")
+)
+
+// Verifier verifies webhook delivery signatures.
+type Verifier struct {
+	secret []byte
+	// ReplayWindow is the maximum accepted difference between the delivery timestamp and the current time.
+	ReplayWindow time.Duration
+	now          func() time.Time
+}
+
+// NewVerifier creates Verifier of deliveries signed with the webhook secret, accepted within DefaultReplayWindow.
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{
+		secret:       []byte(secret),
+		ReplayWindow: DefaultReplayWindow,
+		now:          time.Now,
+	}
+}
+
+// Verify checks the delivery signature and timestamp headers against the body.
+//
+// Returns ErrInvalidSignature error in case the signature or timestamp is missing or does not match.
+// Returns ErrStaleDelivery error in case the timestamp is out of replay window.
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get(TimestampHeaderName)
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	signature := header.Get(SignatureHeaderName)
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal(received, v.sign(timestamp, body)) {
+		return ErrInvalidSignature
+	}
+
+	age := v.now().Sub(time.Unix(seconds, 0))
+	if age > v.ReplayWindow || age < -v.ReplayWindow {
+		return ErrStaleDelivery
+	}
+
+	return nil
+}
+
+// Sign sets the signature and timestamp headers of the delivery, e.g. to test webhook receivers.
+func (v *Verifier) Sign(header http.Header, body []byte) {
+	timestamp := strconv.FormatInt(v.now().Unix(), 10)
+
+	header.Set(TimestampHeaderName, timestamp)
+	header.Set(SignatureHeaderName, signaturePrefix+hex.EncodeToString(v.sign(timestamp, body)))
+}
+
+func (v *Verifier) sign(timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
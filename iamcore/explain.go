@@ -0,0 +1,149 @@
+package iamcore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// AuthorizationExplanation is the detailed authorization decision on resources, see ExplainAuthorization.
+type AuthorizationExplanation struct {
+	Resources            []*ResourceExplanation
+	EvaluationTimeMillis int
+}
+
+// ResourceExplanation is the authorization decision on the resource.
+type ResourceExplanation struct {
+	IRN *irn.IRN
+	// Decision is "allow" in case all the requested actions are granted on the resource, and "deny" otherwise.
+	Decision string
+	Actions  []*ActionExplanation
+}
+
+// ActionExplanation is the authorization decision on the action with the IRNs of policies contributed to the decision.
+type ActionExplanation struct {
+	Action        string
+	Decision      string
+	AllowPolicies []string
+	DenyPolicies  []string
+}
+
+func (c *client) ExplainAuthorization(ctx context.Context, authorizationHeader http.Header, principal *irn.IRN, application string,
+	resources []*irn.IRN, actions []string,
+) (*AuthorizationExplanation, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	responseDTO, err := c.iamcoreClient.EvaluateDebugResourcesAsPrincipal(ctx, authorizationHeader, principal, application, resources, actions)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAuthorizationExplanation(responseDTO), nil
+}
+
+func newAuthorizationExplanation(responseDTO *EvaluateDebugResourcesResponseDTO) *AuthorizationExplanation {
+	explanation := &AuthorizationExplanation{
+		Resources:            make([]*ResourceExplanation, len(responseDTO.Data)),
+		EvaluationTimeMillis: responseDTO.EvaluationTimeMillis,
+	}
+
+	for i, resource := range responseDTO.Data {
+		resourceExplanation := &ResourceExplanation{
+			IRN:      resource.IRN,
+			Decision: resource.Decision,
+			Actions:  make([]*ActionExplanation, len(resource.Actions)),
+		}
+
+		for j, action := range resource.Actions {
+			resourceExplanation.Actions[j] = &ActionExplanation{
+				Action:        action.Action,
+				Decision:      action.Decision,
+				AllowPolicies: action.AllowPolicies,
+				DenyPolicies:  action.DenyPolicies,
+			}
+		}
+
+		explanation.Resources[i] = resourceExplanation
+	}
+
+	return explanation
+}
+
+// Denied returns the denied actions per resource IRN.
+func (e *AuthorizationExplanation) Denied() map[string][]string {
+	denied := make(map[string][]string)
+
+	for _, resource := range e.Resources {
+		for _, action := range resource.Actions {
+			if action.Decision != string(PolicyEffectAllow) {
+				denied[resource.IRN.String()] = append(denied[resource.IRN.String()], action.Action)
+			}
+		}
+	}
+
+	return denied
+}
+
+// WriteTo writes the explanation as a human readable table, one row per resource action.
+// Denied actions not matched by any policy are marked as implicitly denied.
+func (e *AuthorizationExplanation) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	table := tabwriter.NewWriter(counter, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "RESOURCE\tACTION\tDECISION\tALLOW POLICIES\tDENY POLICIES")
+
+	for _, resource := range e.Resources {
+		for _, action := range resource.Actions {
+			decision := action.Decision
+			if decision != string(PolicyEffectAllow) && len(action.DenyPolicies) == 0 {
+				decision += " (implicit)"
+			}
+
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", resource.IRN, action.Action, decision,
+				joinOrDash(action.AllowPolicies), joinOrDash(action.DenyPolicies))
+		}
+	}
+
+	if err := table.Flush(); err != nil {
+		return counter.n, err
+	}
+
+	n, err := fmt.Fprintf(counter.w, "Evaluated in %d ms\n", e.EvaluationTimeMillis)
+
+	return counter.n + int64(n), err
+}
+
+// String returns the explanation as a human readable table, see WriteTo.
+func (e *AuthorizationExplanation) String() string {
+	builder := &strings.Builder{}
+	_, _ = e.WriteTo(builder)
+
+	return builder.String()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+
+	return strings.Join(values, ",")
+}
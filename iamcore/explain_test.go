@@ -0,0 +1,66 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExplainAuthorization(t *testing.T) {
+	var principals []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != evaluateDebugResourcesPath {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		requestDTO := &struct {
+			Principal string `json:"principal"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(requestDTO)
+		principals = append(principals, requestDTO.Principal)
+
+		_, _ = w.Write([]byte(`{"data":[{"id":"d1","irn":"irn:rc73dbh7q0:fleet:acme:device/d1","decision":"deny","actions":[
+			{"action":"fleet:device:read","decision":"allow","allowPolicies":["irn:rc73dbh7q0:iamcore:acme:policy/readers"]},
+			{"action":"fleet:device:delete","decision":"deny"}]}],"evaluationTimeMillis":3}`))
+	}))
+	defer server.Close()
+
+	c := newServerTestClient(t, server)
+
+	d1 := mustIRN(t, "acme", "", "d1")
+
+	explanation, err := c.ExplainAuthorization(context.Background(), nil, nil, "fleet", nil, []string{"fleet:device:read", "fleet:device:delete"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if explanation.EvaluationTimeMillis != 3 || len(explanation.Resources) != 1 || len(explanation.Resources[0].Actions) != 2 {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+
+	denied := explanation.Denied()[d1.String()]
+	if len(denied) != 1 || denied[0] != "fleet:device:delete" {
+		t.Fatalf("unexpected denied actions %v", denied)
+	}
+
+	printed := explanation.String()
+	for _, want := range []string{"irn:rc73dbh7q0:iamcore:acme:policy/readers", "deny (implicit)", "Evaluated in 3 ms"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("expected %q in:\n%s", want, printed)
+		}
+	}
+
+	principal := mustIRN(t, "acme", "", "john")
+
+	if _, err = c.ExplainAuthorization(context.Background(), nil, principal, "fleet", nil, []string{"fleet:device:read"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(principals) != 2 || principals[0] != "" || principals[1] != principal.Base64() {
+		t.Fatalf("expected evaluation as the authenticated principal and then as %s, got %v", principal, principals)
+	}
+}
@@ -2,6 +2,8 @@ package iamcore
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"io"
 	"net"
@@ -25,6 +27,12 @@ const (
 var errNoRewind = errors.New("iamcore: request body cannot be rewound for retry")
 
 func newDefaultHTTPClient() *http.Client {
+	return newHTTPClient(requestTimeout, maxConnRetries, nil)
+}
+
+// newHTTPClient creates the client with the request timeout, the number of retries of requests failed on stale
+// connections, and the root CAs to verify iamcore server certificate; the system roots are used if nil.
+func newHTTPClient(timeout time.Duration, maxRetries int, rootCAs *x509.CertPool) *http.Client {
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
@@ -39,9 +47,13 @@ func newDefaultHTTPClient() *http.Client {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	if rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	}
+
 	return &http.Client{
-		Transport: &retryRoundTripper{base: transport, maxRetries: maxConnRetries},
-		Timeout:   requestTimeout,
+		Transport: &retryRoundTripper{base: transport, maxRetries: maxRetries},
+		Timeout:   timeout,
 	}
 }
 
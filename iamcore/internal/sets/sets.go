@@ -0,0 +1,47 @@
+// Package sets compares string slices as sets, e.g. the operations of resource types.
+package sets
+
+import (
+	"sort"
+	"strings"
+)
+
+// Diff returns the set difference between actual and desired strings as "+added -removed", both sorted;
+// empty if the sets are equal. Empty strings are ignored.
+func Diff(actual, desired []string) string {
+	actualSet := toSet(actual)
+	desiredSet := toSet(desired)
+
+	added := make([]string, 0)
+
+	for value := range desiredSet {
+		if !actualSet[value] {
+			added = append(added, "+"+value)
+		}
+	}
+
+	removed := make([]string, 0)
+
+	for value := range actualSet {
+		if !desiredSet[value] {
+			removed = append(removed, "-"+value)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return strings.Join(append(added, removed...), " ")
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+
+	for _, value := range values {
+		if value != "" {
+			set[value] = true
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,22 @@
+package sets
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		actual, desired []string
+		want            string
+	}{
+		{nil, nil, ""},
+		{[]string{"read", "write"}, []string{"write", "read", "read"}, ""},
+		{[]string{"read"}, []string{"read", "write", "export"}, "+export +write"},
+		{[]string{"import", "read", "delete"}, []string{"read"}, "-delete -import"},
+		{[]string{"import"}, []string{"export"}, "+export -import"},
+		{[]string{"read", ""}, []string{"read"}, ""},
+	}
+	for _, tc := range cases {
+		if got := Diff(tc.actual, tc.desired); got != tc.want {
+			t.Errorf("Diff(%v, %v) = %q, want %q", tc.actual, tc.desired, got, tc.want)
+		}
+	}
+}
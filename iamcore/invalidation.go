@@ -0,0 +1,132 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/events"
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// ChangeEvent notifies about the change on iamcore affecting authorization decisions, e.g. policy update,
+// principal group membership change or resource deletion. Only the IRNs relevant to the change are set.
+// It is converted from iamcore event, see NewChangeEvent.
+type ChangeEvent struct {
+	ID        string
+	Type      string
+	Principal *irn.IRN
+	Policy    *irn.IRN
+	Resource  *irn.IRN
+	Time      time.Time
+}
+
+// changeEventData are the IRNs of iamcore event data relevant to invalidation, whatever the event type is.
+type changeEventData struct {
+	User      *irn.IRN `json:"user"`
+	Principal *irn.IRN `json:"principal"`
+	Policy    *irn.IRN `json:"policy"`
+	Resource  *irn.IRN `json:"resource"`
+}
+
+// NewChangeEvent converts iamcore event, as delivered by webhook or event stream, into ChangeEvent,
+// e.g. to bridge the events consumed from a message broker.
+//
+// Returns events.ErrMalformedEvent error in case the event has no ID or type, or the event data cannot be decoded.
+func NewChangeEvent(event *events.Event) (*ChangeEvent, error) {
+	if event.ID == "" || event.Type == "" {
+		return nil, fmt.Errorf("missing event ID or type: %w", events.ErrMalformedEvent)
+	}
+
+	data := &changeEventData{}
+
+	if len(event.Data) != 0 {
+		if err := json.Unmarshal(event.Data, data); err != nil {
+			return nil, fmt.Errorf("event %s data: %v: %w", event.ID, err, events.ErrMalformedEvent)
+		}
+	}
+
+	principal := data.Principal
+	if principal == nil {
+		principal = data.User
+	}
+
+	return &ChangeEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		Principal: principal,
+		Policy:    data.Policy,
+		Resource:  data.Resource,
+		Time:      event.Time,
+	}, nil
+}
+
+// decodeChangeEvent decodes iamcore event and converts it into ChangeEvent.
+func decodeChangeEvent(payload []byte) (*ChangeEvent, error) {
+	event := &events.Event{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, events.ErrMalformedEvent)
+	}
+
+	return NewChangeEvent(event)
+}
+
+// Invalidator is implemented by client-side caches of principals or authorization decisions
+// to drop the entries affected by iamcore changes.
+type Invalidator interface {
+	InvalidatePrincipal(principal *irn.IRN)
+	InvalidatePolicy(policy *irn.IRN)
+	InvalidateResource(resource *irn.IRN)
+}
+
+// ChangeEventSource delivers iamcore change events, e.g. from webhook, iamcore event stream or a message broker bridge.
+type ChangeEventSource interface {
+	// Run delivers change events to handle until the context is done or the source fails.
+	Run(ctx context.Context, handle func(event *ChangeEvent)) error
+}
+
+// ChangeSubscriber invalidates cached entries on iamcore change events.
+type ChangeSubscriber struct {
+	invalidators []Invalidator
+}
+
+// NewChangeSubscriber creates ChangeSubscriber invalidating entries of all the invalidators.
+func NewChangeSubscriber(invalidators ...Invalidator) *ChangeSubscriber {
+	return &ChangeSubscriber{invalidators: invalidators}
+}
+
+// Run consumes change events of the source until the context is done or the source fails.
+func (s *ChangeSubscriber) Run(ctx context.Context, source ChangeEventSource) error {
+	return source.Run(ctx, s.HandleEvent)
+}
+
+// HandleEvent invalidates the entries related to the principal, policy and resource of the event.
+func (s *ChangeSubscriber) HandleEvent(event *ChangeEvent) {
+	for _, invalidator := range s.invalidators {
+		if event.Principal != nil {
+			invalidator.InvalidatePrincipal(event.Principal)
+		}
+
+		if event.Policy != nil {
+			invalidator.InvalidatePolicy(event.Policy)
+		}
+
+		if event.Resource != nil {
+			invalidator.InvalidateResource(event.Resource)
+		}
+	}
+}
+
+// InvalidatePrincipal requests the policies refresh, as principal's effective policies could change.
+func (e *LocalEvaluator) InvalidatePrincipal(*irn.IRN) {
+	e.Notify()
+}
+
+// InvalidatePolicy requests the policies refresh.
+func (e *LocalEvaluator) InvalidatePolicy(*irn.IRN) {
+	e.Notify()
+}
+
+// InvalidateResource does nothing, as the decisions are evaluated against resource IRNs regardless of the resources state.
+func (e *LocalEvaluator) InvalidateResource(*irn.IRN) {}
@@ -0,0 +1,179 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/events"
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// recordingInvalidator records invalidated IRNs.
+type recordingInvalidator struct {
+	mu          sync.Mutex
+	invalidated []string
+}
+
+func (r *recordingInvalidator) record(kind string, resourceIRN *irn.IRN) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invalidated = append(r.invalidated, kind+" "+resourceIRN.String())
+}
+
+func (r *recordingInvalidator) InvalidatePrincipal(principal *irn.IRN) {
+	r.record("principal", principal)
+}
+
+func (r *recordingInvalidator) InvalidatePolicy(policy *irn.IRN) {
+	r.record("policy", policy)
+}
+
+func (r *recordingInvalidator) InvalidateResource(resource *irn.IRN) {
+	r.record("resource", resource)
+}
+
+func newSignedWebhookRequest(secret, body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	events.NewVerifier(secret).Sign(request.Header, []byte(body))
+
+	return request
+}
+
+func TestWebhookEventSourceInvalidates(t *testing.T) {
+	invalidator := &recordingInvalidator{}
+	subscriber := NewChangeSubscriber(invalidator)
+	source := NewWebhookEventSource("secret")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	body := `{"id":"e1","type":"policy.updated","data":{"policy":"irn:rc73dbh7q0:iamcore:acme::policy/readers"}}`
+
+	recorder := httptest.NewRecorder()
+	source.ServeHTTP(recorder, newSignedWebhookRequest("secret", body))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 Service Unavailable before subscriber runs, got %d", recorder.Code)
+	}
+
+	go func() { _ = subscriber.Run(ctx, source) }()
+
+	for recorder.Code != http.StatusNoContent {
+		recorder = httptest.NewRecorder()
+		source.ServeHTTP(recorder, newSignedWebhookRequest("secret", body))
+	}
+
+	if len(invalidator.invalidated) != 1 || invalidator.invalidated[0] != "policy irn:rc73dbh7q0:iamcore:acme::policy/readers" {
+		t.Fatalf("unexpected invalidations %v", invalidator.invalidated)
+	}
+
+	recorder = httptest.NewRecorder()
+	source.ServeHTTP(recorder, newSignedWebhookRequest("other", body))
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 Unauthorized for invalid signature, got %d", recorder.Code)
+	}
+
+	// The flat change event is not iamcore event payload.
+	recorder = httptest.NewRecorder()
+	source.ServeHTTP(recorder, newSignedWebhookRequest("secret", `{"id":"e2","policy":"irn:rc73dbh7q0:iamcore:acme::policy/readers"}`))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 Bad Request for malformed event, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	source.ServeHTTP(recorder, newSignedWebhookRequest("secret", body+strings.Repeat(" ", maxWebhookBodySize)))
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 Request Entity Too Large for oversized delivery, got %d", recorder.Code)
+	}
+}
+
+func TestNewChangeEvent(t *testing.T) {
+	event := &events.Event{
+		ID:   "e1",
+		Type: events.TypeAPIKeyRevoked,
+		Data: []byte(`{"apiKey":"irn:rc73dbh7q0:iamcore:acme::api-key/k1","principal":"irn:rc73dbh7q0:iamcore:acme::user/john"}`),
+	}
+
+	changeEvent, err := NewChangeEvent(event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if changeEvent.Principal.String() != "irn:rc73dbh7q0:iamcore:acme::user/john" || changeEvent.Policy != nil || changeEvent.Resource != nil {
+		t.Fatalf("unexpected change event %+v", changeEvent)
+	}
+
+	userDeleted := &events.Event{ID: "e2", Type: events.TypeUserDeleted, Data: []byte(`{"user":"irn:rc73dbh7q0:iamcore:acme::user/jane"}`)}
+	if changeEvent, err = NewChangeEvent(userDeleted); err != nil || changeEvent.Principal.String() != "irn:rc73dbh7q0:iamcore:acme::user/jane" {
+		t.Fatalf("expected deleted user as principal, got %+v, %v", changeEvent, err)
+	}
+
+	malformed := &events.Event{ID: "e3", Type: events.TypeUserDeleted, Data: []byte(`"not an object"`)}
+	if _, err = NewChangeEvent(malformed); !errors.Is(err, events.ErrMalformedEvent) {
+		t.Fatalf("expected ErrMalformedEvent, got %v", err)
+	}
+}
+
+func TestStreamEventSourceResumes(t *testing.T) {
+	var lastEventIDs, apiKeys []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		apiKeys = append(apiKeys, r.Header.Get(apiKeyHeaderName))
+
+		if len(lastEventIDs) > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 6\nevent: change\ndata: {malformed\n\n")
+		fmt.Fprint(w, "id: 7\nevent: change\ndata: {\"id\":\"e7\",\"type\":\"resource.deleted\",\"data\":{\"resource\":\"irn:rc73dbh7q0:fleet:acme::device/d1\"}}\n\n")
+		fmt.Fprint(w, "id: 8\nevent: change\ndata: {\"id\":\"e8\",\"type\":\"resource.deleted\",\"data\":{\"resource\":\"not an IRN\"}}\n\n")
+		// The event exceeding the default scanner line limit.
+		fmt.Fprint(w, "id: 9\nevent: change\ndata: {\"id\":\"e9\",\"type\":\"resource.deleted\",\"data\":{\"resource\":"+
+			"\"irn:rc73dbh7q0:fleet:acme::device/d2\",\"comment\":\""+strings.Repeat("x", 100_000)+"\"}}\n\n")
+	}))
+	defer server.Close()
+
+	var connects int
+
+	// The credentials are rotated between the connections.
+	authorizationHeader := func(context.Context) (http.Header, error) {
+		connects++
+
+		return http.Header{apiKeyHeaderName: {fmt.Sprintf("key-%d", connects)}}, nil
+	}
+
+	invalidator := &recordingInvalidator{}
+	source := NewStreamEventSource(server.URL, authorizationHeader, StreamEventSourceOptions{ReconnectInterval: 1})
+
+	err := NewChangeSubscriber(invalidator).Run(context.Background(), source)
+	if !isUnauthorizedError(err) {
+		t.Fatalf("expected unauthenticated error, got %v", err)
+	}
+
+	if len(lastEventIDs) != 2 || lastEventIDs[1] != "9" {
+		t.Fatalf("expected reconnect resuming after large event 9, got %v", lastEventIDs)
+	}
+
+	if apiKeys[0] != "key-1" || apiKeys[1] != "key-2" {
+		t.Fatalf("expected credentials resolved on reconnect, got %v", apiKeys)
+	}
+
+	if len(invalidator.invalidated) != 2 || invalidator.invalidated[0] != "resource irn:rc73dbh7q0:fleet:acme::device/d1" ||
+		invalidator.invalidated[1] != "resource irn:rc73dbh7q0:fleet:acme::device/d2" {
+		t.Fatalf("unexpected invalidations %v", invalidator.invalidated)
+	}
+}
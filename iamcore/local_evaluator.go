@@ -0,0 +1,299 @@
+package iamcore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+const defaultLocalEvaluatorRefreshInterval = time.Minute
+
+// PolicySource fetches the policies compiled by LocalEvaluator. The policies must be the ones in effect for the single
+// principal the evaluator decides for, as all of them are granted to whoever is authorized by the evaluator.
+type PolicySource func(ctx context.Context) ([]*PolicyResponseDTO, error)
+
+// EffectivePoliciesSource returns PolicySource fetching policies in effect for the principal,
+// including the ones inherited from the groups the principal is member of.
+func EffectivePoliciesSource(manager PolicyManager, authorizationHeader http.Header, principalType PrincipalType,
+	principalIRN *irn.IRN,
+) PolicySource {
+	return func(ctx context.Context) ([]*PolicyResponseDTO, error) {
+		return manager.ListEffectivePolicies(ctx, authorizationHeader, principalType, principalIRN)
+	}
+}
+
+// LocalEvaluatorOptions configures LocalEvaluator.
+type LocalEvaluatorOptions struct {
+	// RefreshInterval is the interval of policies refresh by Run; one minute by default.
+	// Negative value disables periodic refresh, so that policies are refreshed on Notify only.
+	RefreshInterval time.Duration
+	// OnRefreshError is called in case Run fails to refresh policies; the error is logged by default.
+	// The previously fetched policies remain in effect.
+	OnRefreshError func(err error)
+}
+
+// LocalEvaluator evaluates authorization decisions in-process against the compiled snapshot of policies,
+// without calling iamcore per decision. Policy statements support "*" wildcard in resource IRNs and actions.
+// Explicit deny takes precedence over allow, and resources not matched by any allow statement are denied.
+// The decisions are the ones of the principal, whose policies are fetched by the source, see EffectivePoliciesSource.
+//
+// The snapshot is refreshed periodically and on change notifications by Run.
+type LocalEvaluator struct {
+	source  PolicySource
+	options LocalEvaluatorOptions
+	notify  chan struct{}
+
+	mu       sync.RWMutex
+	snapshot *policySnapshot
+}
+
+// NewLocalEvaluator creates LocalEvaluator and fetches the initial policies snapshot from the source.
+func NewLocalEvaluator(ctx context.Context, source PolicySource, options LocalEvaluatorOptions) (*LocalEvaluator, error) {
+	if options.RefreshInterval == 0 {
+		options.RefreshInterval = defaultLocalEvaluatorRefreshInterval
+	}
+
+	if options.OnRefreshError == nil {
+		options.OnRefreshError = func(err error) {
+			log.Printf("Error refreshing iamcore policies: %v", err)
+		}
+	}
+
+	evaluator := &LocalEvaluator{
+		source:  source,
+		options: options,
+		notify:  make(chan struct{}, 1),
+	}
+
+	if err := evaluator.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return evaluator, nil
+}
+
+// Refresh fetches the policies from the source and replaces the snapshot.
+func (e *LocalEvaluator) Refresh(ctx context.Context) error {
+	policies, err := e.source(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch policies: %w", err)
+	}
+
+	snapshot, err := compilePolicies(policies)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.snapshot = snapshot
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Notify requests the policies refresh by Run, e.g. on iamcore policy change notification. It never blocks.
+func (e *LocalEvaluator) Notify() {
+	select {
+	case e.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the policies periodically and on Notify until the context is done.
+func (e *LocalEvaluator) Run(ctx context.Context) {
+	var ticks <-chan time.Time
+
+	if e.options.RefreshInterval > 0 {
+		ticker := time.NewTicker(e.options.RefreshInterval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+		case <-e.notify:
+		}
+
+		if err := e.Refresh(ctx); err != nil && ctx.Err() == nil {
+			e.options.OnRefreshError(err)
+		}
+	}
+}
+
+// IsAllowed reports whether the action is granted on the resource.
+func (e *LocalEvaluator) IsAllowed(resource *irn.IRN, action string) bool {
+	e.mu.RLock()
+	snapshot := e.snapshot
+	e.mu.RUnlock()
+
+	return snapshot.isAllowed(resource.String(), action)
+}
+
+// Authorize returns requested resources if the action is granted on ALL of them, the same way AuthorizationClient.Authorize does.
+// Unlike the remote client, resources of the type cannot be listed locally, so resourceIDs must not be empty.
+//
+// Returns ErrForbidden error in case the action is not granted on any of requested resources.
+// Returns ErrBadRequest error in case of invalid request.
+func (e *LocalEvaluator) Authorize(accountID, application, tenantID, resourceType, resourcePath string, resourceIDs []string,
+	action string,
+) ([]string, error) {
+	if len(resourceIDs) == 0 {
+		return nil, fmt.Errorf("resource IDs are required for local evaluation: %w", ErrBadRequest)
+	}
+
+	authorizedResourceIDs, err := e.FilterAuthorizedResources(accountID, application, tenantID, resourceType, resourcePath, resourceIDs, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(authorizedResourceIDs) != len(resourceIDs) {
+		return nil, ErrForbidden
+	}
+
+	return resourceIDs, nil
+}
+
+// FilterAuthorizedResources returns a subset of resources, on which the action is granted,
+// the same way AuthorizationClient.FilterAuthorizedResources does.
+//
+// Returns ErrBadRequest error in case of invalid request.
+func (e *LocalEvaluator) FilterAuthorizedResources(accountID, application, tenantID, resourceType, resourcePath string, resourceIDs []string,
+	action string,
+) ([]string, error) {
+	resourceIRNs, err := buildResourceIRNs(accountID, application, tenantID, resourceType, resourcePath, resourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrBadRequest)
+	}
+
+	authorizedResourceIDs := make([]string, 0, len(resourceIRNs))
+
+	for _, resourceIRN := range resourceIRNs {
+		if e.IsAllowed(resourceIRN, action) {
+			authorizedResourceIDs = append(authorizedResourceIDs, resourceIRN.GetResourceID())
+		}
+	}
+
+	return authorizedResourceIDs, nil
+}
+
+// ConformanceMismatch is the decision of LocalEvaluator differing from iamcore evaluation.
+type ConformanceMismatch struct {
+	Resource       *irn.IRN
+	Action         string
+	LocalDecision  string
+	RemoteDecision string
+}
+
+// CheckConformance compares local decisions with the result of iamcore debug evaluation (see ServerClient.EvaluateDebugResources)
+// over the same principal policies, and returns the mismatching decisions.
+func (e *LocalEvaluator) CheckConformance(remote *EvaluateDebugResourcesResponseDTO) []*ConformanceMismatch {
+	mismatches := make([]*ConformanceMismatch, 0)
+
+	for _, resource := range remote.Data {
+		for _, action := range resource.Actions {
+			localDecision := string(PolicyEffectDeny)
+			if e.IsAllowed(resource.IRN, action.Action) {
+				localDecision = string(PolicyEffectAllow)
+			}
+
+			if localDecision != action.Decision {
+				mismatches = append(mismatches, &ConformanceMismatch{
+					Resource:       resource.IRN,
+					Action:         action.Action,
+					LocalDecision:  localDecision,
+					RemoteDecision: action.Decision,
+				})
+			}
+		}
+	}
+
+	return mismatches
+}
+
+// policySnapshot is the compiled set of policy statements.
+type policySnapshot struct {
+	allow []*compiledStatement
+	deny  []*compiledStatement
+}
+
+type compiledStatement struct {
+	resources []*regexp.Regexp
+	actions   []*regexp.Regexp
+}
+
+func compilePolicies(policies []*PolicyResponseDTO) (*policySnapshot, error) {
+	snapshot := &policySnapshot{}
+
+	for _, policy := range policies {
+		for i, statement := range policy.Statements {
+			compiled := &compiledStatement{
+				resources: compileWildcards(statement.Resources),
+				actions:   compileWildcards(statement.Actions),
+			}
+
+			switch statement.Effect {
+			case PolicyEffectAllow:
+				snapshot.allow = append(snapshot.allow, compiled)
+			case PolicyEffectDeny:
+				snapshot.deny = append(snapshot.deny, compiled)
+			default:
+				return nil, fmt.Errorf("policy %q statement #%d: unknown effect %q", policy.Name, i, statement.Effect)
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+func (s *policySnapshot) isAllowed(resource, action string) bool {
+	for _, statement := range s.deny {
+		if statement.matches(resource, action) {
+			return false
+		}
+	}
+
+	for _, statement := range s.allow {
+		if statement.matches(resource, action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *compiledStatement) matches(resource, action string) bool {
+	return matchesAny(s.actions, action) && matchesAny(s.resources, resource)
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compileWildcards compiles patterns where "*" matches any sequence of characters.
+func compileWildcards(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+
+	for i, pattern := range patterns {
+		expression := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		compiled[i] = regexp.MustCompile("^" + expression + "$")
+	}
+
+	return compiled
+}
@@ -0,0 +1,169 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func mustIRN(t *testing.T, tenantID, path, resourceID string) *irn.IRN {
+	t.Helper()
+
+	resourceIRN, err := irn.NewIRN("rc73dbh7q0", "fleet", tenantID, nil, "device", irn.SplitPath(path), resourceID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return resourceIRN
+}
+
+func staticPolicies(statements ...*PolicyStatementDTO) PolicySource {
+	return func(context.Context) ([]*PolicyResponseDTO, error) {
+		return []*PolicyResponseDTO{{Name: "test", Statements: statements}}, nil
+	}
+}
+
+// TestLocalEvaluatorCheckConformance checks local decisions against debug evaluation results written by hand
+// after iamcore evaluation semantics; they are not recorded from iamcore.
+func TestLocalEvaluatorCheckConformance(t *testing.T) {
+	d1 := mustIRN(t, "acme", "/eu", "d1")
+	d2 := mustIRN(t, "acme", "/eu", "d2")
+	d3 := mustIRN(t, "other", "/us", "d3")
+	wildcardEU := strings.TrimSuffix(d1.String(), "d1") + "*"
+
+	tests := []struct {
+		name       string
+		statements []*PolicyStatementDTO
+		remote     []*DebugEvaluationResourceItem
+	}{
+		{
+			name: "wildcard resources and actions",
+			statements: []*PolicyStatementDTO{
+				{Effect: PolicyEffectAllow, Resources: []string{wildcardEU}, Actions: []string{"fleet:device:*"}},
+			},
+			remote: []*DebugEvaluationResourceItem{
+				{IRN: d1, Actions: []*DebugEvaluationActionDetail{{Action: "fleet:device:read", Decision: "allow"}}},
+				{IRN: d2, Actions: []*DebugEvaluationActionDetail{{Action: "fleet:device:delete", Decision: "allow"}}},
+				{IRN: d3, Actions: []*DebugEvaluationActionDetail{{Action: "fleet:device:read", Decision: "deny"}}},
+			},
+		},
+		{
+			name: "explicit deny takes precedence",
+			statements: []*PolicyStatementDTO{
+				{Effect: PolicyEffectAllow, Resources: []string{"*"}, Actions: []string{"*"}},
+				{Effect: PolicyEffectDeny, Resources: []string{d2.String()}, Actions: []string{"fleet:device:delete"}},
+			},
+			remote: []*DebugEvaluationResourceItem{
+				{IRN: d2, Actions: []*DebugEvaluationActionDetail{
+					{Action: "fleet:device:read", Decision: "allow"},
+					{Action: "fleet:device:delete", Decision: "deny"},
+				}},
+			},
+		},
+		{
+			name:       "implicit deny",
+			statements: nil,
+			remote: []*DebugEvaluationResourceItem{
+				{IRN: d1, Actions: []*DebugEvaluationActionDetail{{Action: "fleet:device:read", Decision: "deny"}}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evaluator, err := NewLocalEvaluator(context.Background(), staticPolicies(test.statements...), LocalEvaluatorOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, mismatch := range evaluator.CheckConformance(&EvaluateDebugResourcesResponseDTO{Data: test.remote}) {
+				t.Errorf("%s %s: local %s, iamcore %s", mismatch.Resource, mismatch.Action, mismatch.LocalDecision, mismatch.RemoteDecision)
+			}
+		})
+	}
+}
+
+func TestLocalEvaluatorCheckConformanceMismatch(t *testing.T) {
+	d1 := mustIRN(t, "acme", "/eu", "d1")
+
+	evaluator, err := NewLocalEvaluator(context.Background(), staticPolicies(), LocalEvaluatorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	remote := &EvaluateDebugResourcesResponseDTO{Data: []*DebugEvaluationResourceItem{
+		{IRN: d1, Actions: []*DebugEvaluationActionDetail{{Action: "fleet:device:read", Decision: "allow"}}},
+	}}
+
+	mismatches := evaluator.CheckConformance(remote)
+	if len(mismatches) != 1 || mismatches[0].LocalDecision != "deny" || mismatches[0].RemoteDecision != "allow" {
+		t.Fatalf("expected implicit deny reported as mismatch, got %+v", mismatches)
+	}
+}
+
+func TestLocalEvaluatorAuthorize(t *testing.T) {
+	d1 := mustIRN(t, "acme", "/eu", "d1")
+
+	evaluator, err := NewLocalEvaluator(context.Background(), staticPolicies(
+		&PolicyStatementDTO{Effect: PolicyEffectAllow, Resources: []string{d1.String()}, Actions: []string{"fleet:device:read"}},
+	), LocalEvaluatorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resourceIDs, err := evaluator.FilterAuthorizedResources("rc73dbh7q0", "fleet", "acme", "device", "/eu", []string{"d1", "d2"}, "fleet:device:read")
+	if err != nil || len(resourceIDs) != 1 || resourceIDs[0] != "d1" {
+		t.Fatalf("unexpected filtered resources %v, error %v", resourceIDs, err)
+	}
+
+	if _, err = evaluator.Authorize("rc73dbh7q0", "fleet", "acme", "device", "/eu", []string{"d1", "d2"}, "fleet:device:read"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	if _, err = evaluator.Authorize("rc73dbh7q0", "fleet", "acme", "device", "/eu", nil, "fleet:device:read"); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+}
+
+func TestLocalEvaluatorEffectivePoliciesOfPrincipal(t *testing.T) {
+	john, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "user", nil, "john")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jane, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "user", nil, "jane")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d1 := mustIRN(t, "acme", "/eu", "d1")
+
+	// Both policies are in the tenant, but the one granting delete is attached to jane only.
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + userPath + "/" + john.Base64() + "/policies": {
+			http.StatusOK, `{"data":[{"name":"readers","statements":[{"effect":"allow","resources":["*"],"actions":["fleet:device:read"]}]}]}`,
+		},
+		"GET " + userPath + "/" + jane.Base64() + "/policies": {
+			http.StatusOK, `{"data":[{"name":"admins","statements":[{"effect":"allow","resources":["*"],"actions":["*"]}]}]}`,
+		},
+	})
+
+	source := EffectivePoliciesSource(newServerTestClient(t, server.Server), nil, PrincipalTypeUser, john)
+
+	evaluator, err := NewLocalEvaluator(context.Background(), source, LocalEvaluatorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !evaluator.IsAllowed(d1, "fleet:device:read") {
+		t.Fatal("expected action of john's policy allowed")
+	}
+
+	if evaluator.IsAllowed(d1, "fleet:device:delete") {
+		t.Fatal("expected action of the policy attached to another user denied")
+	}
+}
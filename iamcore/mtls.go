@@ -0,0 +1,103 @@
+package iamcore
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+const mtlsAuthenticatorName = "mtls"
+
+// ErrCredentialsNotForwardable is returned by GetPrincipalAuthorizationHeader in case the principal is authenticated
+// by the client certificate, which cannot be forwarded to iamcore. Such principals are authorized with the service
+// credentials on behalf of the principal, e.g. by means of EvaluateActionsOnResourcesByPrincipal.
+var ErrCredentialsNotForwardable = errors.New("principal credentials cannot be forwarded to iamcore")
+
+// CertificateMapper maps the verified client certificate to principal IRN.
+// Returns nil IRN in case the certificate is not mapped to any principal.
+type CertificateMapper func(ctx context.Context, certificate *x509.Certificate) (*irn.IRN, error)
+
+// MTLS authenticates requests by means of the verified mutual TLS client certificate.
+// The request must be served by the TLS server verifying client certificates, i.e. configured with
+// tls.RequireAndVerifyClientCert or tls.VerifyClientCertIfGiven client authentication.
+// The principal has no authorization header to call iamcore with, see ErrCredentialsNotForwardable.
+type MTLS struct {
+	mapper CertificateMapper
+}
+
+func NewMTLS(mapper CertificateMapper) *MTLS {
+	return &MTLS{
+		mapper: mapper,
+	}
+}
+
+// Authenticate skips the request, as client certificate is not available from the request header.
+func (a *MTLS) Authenticate(_ context.Context, _ http.Header) (*Principal, http.Header, error) {
+	return nil, nil, nil
+}
+
+func (a *MTLS) AuthenticateRequest(r *http.Request) (*Principal, http.Header, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil, nil
+	}
+
+	if len(r.TLS.VerifiedChains) == 0 {
+		return nil, nil, fmt.Errorf("client certificate is not verified: %w", ErrUnauthenticated)
+	}
+
+	certificate := r.TLS.PeerCertificates[0]
+
+	principalIRN, err := a.mapper(r.Context(), certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if principalIRN == nil {
+		// The certificate identity is logged rather than returned, as the error message reaches the response body.
+		log.Printf("Client certificate %q is not mapped to any iamcore principal", certificate.Subject.CommonName)
+
+		return nil, nil, ErrUnauthenticated
+	}
+
+	return &Principal{
+		IRN:           principalIRN,
+		Kind:          PrincipalKindServiceAccount,
+		Authenticator: mtlsAuthenticatorName,
+		ExpiresAt:     certificate.NotAfter,
+	}, nil, nil
+}
+
+// StaticCertificateMapper maps client certificate identities to principal IRNs by the fixed mapping.
+// The identity is either SAN URI (e.g. "spiffe://cluster.local/ns/default/sa/billing") or subject common name;
+// SAN URIs take precedence.
+func StaticCertificateMapper(mapping map[string]*irn.IRN) CertificateMapper {
+	return func(_ context.Context, certificate *x509.Certificate) (*irn.IRN, error) {
+		for _, identity := range certificateIdentities(certificate) {
+			if principalIRN, ok := mapping[identity]; ok {
+				return principalIRN, nil
+			}
+		}
+
+		return nil, nil
+	}
+}
+
+// certificateIdentities returns the identities of the certificate in the order of precedence: SAN URIs, subject common name.
+func certificateIdentities(certificate *x509.Certificate) []string {
+	identities := make([]string, 0, len(certificate.URIs)+1)
+
+	for _, uri := range certificate.URIs {
+		identities = append(identities, uri.String())
+	}
+
+	if certificate.Subject.CommonName != "" {
+		identities = append(identities, certificate.Subject.CommonName)
+	}
+
+	return identities
+}
@@ -0,0 +1,70 @@
+package iamcore
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func TestMTLSStaticCertificateMapper(t *testing.T) {
+	principalIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "", nil, "user", nil, "billing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spiffeID, _ := url.Parse("spiffe://cluster.local/ns/default/sa/billing")
+	certificate := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "unmapped"},
+		URIs:    []*url.URL{spiffeID},
+	}
+
+	authenticator := NewMTLS(StaticCertificateMapper(map[string]*irn.IRN{spiffeID.String(): principalIRN}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{certificate},
+		VerifiedChains:   [][]*x509.Certificate{{certificate}},
+	}
+
+	principal, _, err := authenticator.AuthenticateRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if principal.IRN != principalIRN || !principal.IsServiceAccount() {
+		t.Fatalf("unexpected principal: %+v", principal)
+	}
+
+	authenticator = NewMTLS(StaticCertificateMapper(nil))
+
+	if _, _, err = authenticator.AuthenticateRequest(request); err != ErrUnauthenticated {
+		t.Fatalf("expected bare ErrUnauthenticated for unmapped certificate, got %v", err)
+	}
+
+	request.TLS.VerifiedChains = nil
+
+	if _, _, err = authenticator.AuthenticateRequest(request); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated for unverified certificate, got %v", err)
+	}
+}
+
+func TestMTLSPrincipalAuthorizationHeader(t *testing.T) {
+	principalIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "", nil, "user", nil, "billing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := newTestClient(t)
+	ctx := withPrincipal(context.Background(), &Principal{IRN: principalIRN, Authenticator: mtlsAuthenticatorName}, nil)
+
+	if _, err = c.GetPrincipalAuthorizationHeader(ctx); !errors.Is(err, ErrCredentialsNotForwardable) {
+		t.Fatalf("expected ErrCredentialsNotForwardable, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuth2TokenPath is the path of iamcore OAuth 2.0 token endpoint.
+const OAuth2TokenPath = "/api/v1/oauth2/token"
+
+// OAuth2Error is an error response of OAuth 2.0 token endpoint as defined by RFC 6749, section 5.2.
+// It wraps ErrUnauthenticated in case of 401 status, invalid credentials or grant, ErrForbidden in case of 403 status
+// or the client is not allowed the requested grant, audience or scope, and ErrBadRequest or ErrUnknown otherwise.
+type OAuth2Error struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth2: %s", e.Code)
+	}
+
+	return fmt.Sprintf("oauth2: %s: %s", e.Code, e.Description)
+}
+
+func (e *OAuth2Error) Unwrap() error {
+	// The status code is authoritative, as the error code is missing from non-JSON responses, e.g. of proxies.
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case http.StatusForbidden:
+		return ErrForbidden
+	}
+
+	switch e.Code {
+	case "invalid_client", "invalid_grant", "invalid_token":
+		return ErrUnauthenticated
+	case "unauthorized_client", "invalid_scope", "invalid_target":
+		return ErrForbidden
+	}
+
+	if e.StatusCode == http.StatusBadRequest {
+		return ErrBadRequest
+	}
+
+	return ErrUnknown
+}
+
+// oauth2Token is the successful response of OAuth 2.0 token endpoint.
+type oauth2Token struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	IssuedTokenType string `json:"issued_token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope"`
+}
+
+// expiresAt returns the token expiry relative to the time of the request; zero if the lifetime is not known.
+func (t *oauth2Token) expiresAt(requested time.Time) time.Time {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return requested.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// requestOAuth2Token posts the form to the token endpoint, authenticating the client with HTTP Basic
+// authentication in case the client ID is set.
+func requestOAuth2Token(ctx context.Context, httpClient *http.Client, tokenURL string, form url.Values, clientID, clientSecret string) (
+	*oauth2Token, error,
+) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if clientID != "" {
+		request.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		errorDTO := &struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}{}
+
+		if err = json.NewDecoder(response.Body).Decode(errorDTO); err != nil || errorDTO.Error == "" {
+			errorDTO.Error = http.StatusText(response.StatusCode)
+		}
+
+		return nil, &OAuth2Error{
+			StatusCode:  response.StatusCode,
+			Code:        errorDTO.Error,
+			Description: errorDTO.ErrorDescription,
+		}
+	}
+
+	token := &oauth2Token{}
+	if err = json.NewDecoder(response.Body).Decode(token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response without access token: %w", ErrUnknown)
+	}
+
+	return token, nil
+}
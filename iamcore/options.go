@@ -1,17 +1,86 @@
 package iamcore
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 )
 
-var ErrEmptyAPIKey = errors.New("empty API key")
+var (
+	ErrEmptyAPIKey              = errors.New("empty API key")
+	ErrInvalidServerURL         = errors.New("invalid iamcore server URL")
+	ErrNilAuthenticatorsFactory = errors.New("nil authenticators factory")
+)
 
 type Options struct {
 	// serverURL to access the iamcore; "https://cloud.iamcore.io" by default.
 	serverURL string
-	// API key for outbound HTTP requests to secured by iamcore applications or iamcore itself
-	apiKey string
+	// credentials resolve API key for outbound HTTP requests to secured by iamcore applications or iamcore itself
+	credentials CredentialProvider
+	// tokenSource supplies access tokens for outbound HTTP requests instead of API key, if set.
+	tokenSource TokenSource
+	// authenticatorsFactory builds WithAuth authenticator chain; DefaultAuthenticators by default.
+	authenticatorsFactory AuthenticatorsFactory
+	// tryAllAuthenticators makes WithAuth try the next authenticator when present credentials are rejected.
+	tryAllAuthenticators bool
+	// anonymousPolicy defines how WithAuth treats requests without credentials; resolved on iamcore by default.
+	anonymousPolicy *AnonymousPolicy
+	// errorHandler writes error responses of WithAuth and HandleError; JSONErrorHandler by default.
+	errorHandler ErrorHandler
+	// upstreamErrorStatus is the status code of responses to iamcore server failures; 500 by default.
+	upstreamErrorStatus int
+	// timeout of requests to iamcore; 30 seconds by default.
+	timeout time.Duration
+	// maxRetries of requests to iamcore failed on stale connections; 1 by default.
+	maxRetries int
+	// rootCAs verify iamcore server certificate; the system roots by default.
+	rootCAs *x509.CertPool
+}
+
+// Option configures the client created by NewClient.
+type Option func(*Options)
+
+// WithAuthenticators replaces the default authenticator chain used by WithAuth.
+// Authenticators are tried in the order they are returned by the factory, which must not be nil.
+func WithAuthenticators(factory AuthenticatorsFactory) Option {
+	return func(o *Options) {
+		o.authenticatorsFactory = factory
+	}
+}
+
+// WithStopOnFirstCredential controls whether WithAuth stops on the first authenticator that finds credentials
+// in the request. Enabled by default: the request is rejected as soon as the present credentials are rejected.
+// When disabled, the next authenticators are tried and the request is rejected only if none of them succeeds.
+func WithStopOnFirstCredential(stop bool) Option {
+	return func(o *Options) {
+		o.tryAllAuthenticators = !stop
+	}
+}
+
+// WithTimeout sets the timeout of requests to iamcore; 30 seconds by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxRetries sets the number of retries of requests to iamcore failed on stale connections; 1 by default.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *Options) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithRootCAs sets the root CAs to verify iamcore server certificate, e.g. of on-premise installation with private CA.
+func WithRootCAs(rootCAs *x509.CertPool) Option {
+	return func(o *Options) {
+		o.rootCAs = rootCAs
+	}
 }
 
 const (
@@ -21,25 +90,74 @@ const (
 	iamcoreDefaultURL = "https://cloud.iamcore.io"
 )
 
-func newOptions(apiKey, serverURL string) (*Options, error) {
-	if apiKey == "" {
-		apiKey = os.Getenv(apiKeyEnvKey)
+func newOptions(apiKey, serverURL string, opts ...Option) (options *Options, err error) {
+	if serverURL == "" {
+		serverURL = os.Getenv(iamcoreURLEnvKey)
 	}
 
-	if apiKey == "" {
+	if serverURL == "" {
+		serverURL = iamcoreDefaultURL
+	}
+
+	options = &Options{
+		serverURL:             serverURL,
+		authenticatorsFactory: DefaultAuthenticators,
+		errorHandler:          JSONErrorHandler,
+		upstreamErrorStatus:   http.StatusInternalServerError,
+		timeout:               requestTimeout,
+		maxRetries:            maxConnRetries,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// The API key passed explicitly takes precedence over the environment, which is read on each use to pick up rotated keys.
+	if options.credentials == nil {
+		switch {
+		case apiKey != "":
+			options.credentials = StaticCredentials(apiKey)
+		case os.Getenv(apiKeyEnvKey) != "":
+			options.credentials = EnvCredentials(apiKeyEnvKey)
+		}
+	}
+
+	if options.credentials == nil && options.tokenSource == nil {
 		return nil, ErrEmptyAPIKey
 	}
 
-	if serverURL == "" {
-		serverURL = os.Getenv(iamcoreURLEnvKey)
+	if options.serverURL, err = validateServerURL(options.serverURL); err != nil {
+		return nil, err
 	}
 
-	if serverURL == "" {
-		serverURL = iamcoreDefaultURL
+	if options.authenticatorsFactory == nil {
+		return nil, ErrNilAuthenticatorsFactory
+	}
+
+	if options.anonymousPolicy != nil {
+		if err = options.anonymousPolicy.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+// validateServerURL checks the server URL is absolute HTTP(S) URL, and returns it without trailing slash,
+// as API paths are appended to it.
+func validateServerURL(serverURL string) (string, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", err, ErrInvalidServerURL)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%q must be absolute http or https URL: %w", serverURL, ErrInvalidServerURL)
+	}
+
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("%q must not have query or fragment: %w", serverURL, ErrInvalidServerURL)
 	}
 
-	return &Options{
-		serverURL: serverURL,
-		apiKey:    apiKey,
-	}, nil
+	return strings.TrimRight(serverURL, "/"), nil
 }
@@ -0,0 +1,151 @@
+package iamcore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	permissionMatrixResourceIDParam = "resourceID"
+	permissionMatrixActionParam     = "action"
+	// maxPermissionMatrixValues limits resources and actions of the single request, as all of them are evaluated at once.
+	maxPermissionMatrixValues = 100
+)
+
+// ResourceRef references resources of the same type located at the same path.
+type ResourceRef struct {
+	AccountID    string
+	Application  string
+	TenantID     string
+	ResourceType string
+	Path         string
+}
+
+// PermissionMatrix tells whether each action is granted on each resource, keyed by resource ID and then by action.
+// It is marshalled to JSON as is, e.g. {"d1": {"view": true, "delete": false}}, to be sent to the browser.
+type PermissionMatrix map[string]map[string]bool
+
+// Allowed reports whether the action is granted on the resource.
+func (m PermissionMatrix) Allowed(resourceID, action string) bool {
+	return m[resourceID][action]
+}
+
+// ResourceRefResolver resolves the resources referenced by the request to PermissionMatrixHandler.
+type ResourceRefResolver func(r *http.Request) (ResourceRef, error)
+
+func (c *client) PermissionMatrix(ctx context.Context, authorizationHeader http.Header, resourceRef ResourceRef, resourceIDs,
+	actions []string,
+) (PermissionMatrix, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	resourceIRNs, err := buildResourceIRNs(resourceRef.AccountID, resourceRef.Application, resourceRef.TenantID,
+		resourceRef.ResourceType, resourceRef.Path, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated, err := c.iamcoreClient.EvaluateActionsOnIRNs(ctx, authorizationHeader, actions, resourceIRNs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every requested resource and action is present in the matrix, so that absent entries are never mistaken for undefined.
+	matrix := make(PermissionMatrix, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		matrix[resourceID] = make(map[string]bool, len(actions))
+
+		for _, action := range actions {
+			matrix[resourceID][action] = false
+		}
+	}
+
+	for action, irns := range evaluated {
+		if irns == nil {
+			continue
+		}
+
+		for _, allowed := range irns.Allowed {
+			if permissions, ok := matrix[allowed.GetResourceID()]; ok {
+				permissions[action] = true
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+// PermissionMatrixHandler creates http.Handler responding with JSON PermissionMatrix of the authenticated principal,
+// e.g. for SPA to decide which controls to show. The resources and actions are passed as repeated or comma separated
+// "resourceID" and "action" query parameters, up to 100 distinct values of each, and the rest of the resource reference is resolved from the request.
+// The handler must be wrapped with WithAuth; errors are written by means of the configured ErrorHandler.
+func (c *client) PermissionMatrixHandler(resolve ResourceRefResolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resourceIDs := queryParamValues(r, permissionMatrixResourceIDParam)
+		actions := queryParamValues(r, permissionMatrixActionParam)
+
+		if len(resourceIDs) == 0 || len(actions) == 0 {
+			err := fmt.Errorf("%q and %q query parameters are required: %w", permissionMatrixResourceIDParam, permissionMatrixActionParam, ErrBadRequest)
+			c.HandleError(w, r, err)
+
+			return
+		}
+
+		if len(resourceIDs) > maxPermissionMatrixValues || len(actions) > maxPermissionMatrixValues {
+			err := fmt.Errorf("at most %d resource IDs and actions are allowed: %w", maxPermissionMatrixValues, ErrBadRequest)
+			c.HandleError(w, r, err)
+
+			return
+		}
+
+		resourceRef, err := resolve(r)
+		if err != nil {
+			c.HandleError(w, r, err)
+
+			return
+		}
+
+		authorizationHeader, err := c.GetPrincipalAuthorizationHeader(r.Context())
+		if err != nil {
+			c.HandleError(w, r, err)
+
+			return
+		}
+
+		matrix, err := c.PermissionMatrix(r.Context(), authorizationHeader, resourceRef, resourceIDs, actions)
+		if err != nil {
+			c.HandleError(w, r, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err = json.NewEncoder(w).Encode(matrix); err != nil {
+			log.Printf("Error writing permission matrix: %v", err)
+		}
+	})
+}
+
+// queryParamValues returns the distinct values of the repeated or comma separated query parameter in the order of appearance.
+func queryParamValues(r *http.Request, name string) []string {
+	values := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, param := range r.URL.Query()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,86 @@
+package iamcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPermissionMatrixHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != evaluateActionsOnIRNsPath {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"fleet:device:view":{"allowed":["irn:rc73dbh7q0:fleet:acme:device/d1","irn:rc73dbh7q0:fleet:acme:device/d2"]},` +
+			`"fleet:device:delete":{"allowed":["irn:rc73dbh7q0:fleet:acme:device/d2"],"denied":["irn:rc73dbh7q0:fleet:acme:device/d1"]}}`))
+	}))
+	defer server.Close()
+
+	c := newServerTestClient(t, server, WithAuthenticators(func(*ServerClient) []Authenticator {
+		return []Authenticator{&stubAuthenticator{principal: &Principal{Kind: PrincipalKindUser}}}
+	}))
+
+	handler := c.WithAuth(c.PermissionMatrixHandler(func(*http.Request) (ResourceRef, error) {
+		return ResourceRef{AccountID: "rc73dbh7q0", Application: "fleet", TenantID: "acme", ResourceType: "device"}, nil
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/permissions?resourceID=d1,d2&resourceID=d3&action=fleet:device:view&action=fleet:device:delete", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	matrix := PermissionMatrix{}
+	if err := json.NewDecoder(recorder.Body).Decode(&matrix); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := PermissionMatrix{
+		"d1": {"fleet:device:view": true, "fleet:device:delete": false},
+		"d2": {"fleet:device:view": true, "fleet:device:delete": true},
+		"d3": {"fleet:device:view": false, "fleet:device:delete": false},
+	}
+
+	for resourceID, permissions := range want {
+		for action, allowed := range permissions {
+			if got, ok := matrix[resourceID][action]; !ok || got != allowed {
+				t.Errorf("%s %s: got %t (present %t), want %t", resourceID, action, got, ok, allowed)
+			}
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/permissions?action=fleet:device:view", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 Bad Request without resource IDs, got %d", recorder.Code)
+	}
+
+	distinctIDs := make([]string, maxPermissionMatrixValues+1)
+	for i := range distinctIDs {
+		distinctIDs[i] = fmt.Sprintf("d%d", i)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/permissions?action=fleet:device:view&resourceID="+strings.Join(distinctIDs, ","), nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 Bad Request for too many resource IDs, got %d", recorder.Code)
+	}
+
+	// The duplicated values do not count towards the limit.
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/permissions?action=fleet:device:view&action=fleet:device:view&resourceID="+strings.Repeat("d1,", maxPermissionMatrixValues)+"d2", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected duplicated resource IDs accepted, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,263 @@
+package iamcore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// PolicyEffect is the effect of policy statement.
+type PolicyEffect string
+
+const (
+	PolicyEffectAllow PolicyEffect = "allow"
+	PolicyEffectDeny  PolicyEffect = "deny"
+)
+
+// PrincipalType is the type of principal policies are attached to.
+type PrincipalType string
+
+const (
+	PrincipalTypeUser   PrincipalType = "user"
+	PrincipalTypeGroup  PrincipalType = "group"
+	PrincipalTypeAPIKey PrincipalType = "api-key"
+)
+
+// PolicyFilter filters policies listed by ListPolicies. Empty fields are not applied.
+type PolicyFilter struct {
+	Name     string
+	TenantID string
+	// Page number, starting from 1; the first page by default.
+	Page int
+	// PageSize is the maximum number of policies in the page; iamcore default if zero.
+	PageSize int
+}
+
+type PolicyManager interface {
+	// CreatePolicy creates policy on iamcore; account level policy is created if tenantID is empty.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated policy found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the policy.
+	// Returns ErrBadRequest error in case of invalid request, e.g. unknown statement effect.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreatePolicy(ctx context.Context, authorizationHeader http.Header, tenantID, name, description string,
+		statements []*PolicyStatementDTO) (*PolicyResponseDTO, error)
+
+	// GetPolicy retrieves policy from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case policy does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the policy.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	GetPolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) (*PolicyResponseDTO, error)
+
+	// ListPolicies retrieves a page of policies matching the filter from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read policies.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListPolicies(ctx context.Context, authorizationHeader http.Header, filter PolicyFilter) (*PoliciesResponseDTO, error)
+
+	// UpdatePolicy replaces description and statements of policy on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case policy does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the policy.
+	// Returns ErrBadRequest error in case of invalid request, e.g. unknown statement effect.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	UpdatePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN, description string, statements []*PolicyStatementDTO) error
+
+	// DeletePolicy deletes policy on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case policy does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to delete the policy.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	DeletePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) error
+
+	// AttachPolicies attaches policies to user, group or API key principal.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case principal or any of policies does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to attach policies.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	AttachPolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN, policyIRNs []*irn.IRN) error
+
+	// DetachPolicies detaches policies from user, group or API key principal.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case principal does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to detach policies.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	DetachPolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN, policyIRNs []*irn.IRN) error
+
+	// ListEffectivePolicies retrieves policies in effect for user, group or API key principal,
+	// including the ones inherited from the groups the principal is member of.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case principal does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read policies.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListEffectivePolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN) ([]*PolicyResponseDTO, error)
+}
+
+func (c *client) CreatePolicy(ctx context.Context, authorizationHeader http.Header, tenantID, name, description string,
+	statements []*PolicyStatementDTO,
+) (*PolicyResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	if err := validatePolicyStatements(statements); err != nil {
+		return nil, err
+	}
+
+	createPolicyRequestDTO := &CreatePolicyRequestDTO{
+		Name:        name,
+		Description: description,
+		TenantID:    tenantID,
+		Statements:  statements,
+	}
+
+	return c.iamcoreClient.CreatePolicy(ctx, authorizationHeader, createPolicyRequestDTO)
+}
+
+func (c *client) GetPolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) (*PolicyResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetPolicy(ctx, authorizationHeader, policyIRN)
+}
+
+func (c *client) ListPolicies(ctx context.Context, authorizationHeader http.Header, filter PolicyFilter) (*PoliciesResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetPolicies(ctx, authorizationHeader, filter.query())
+}
+
+func (c *client) UpdatePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN, description string,
+	statements []*PolicyStatementDTO,
+) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	if err := validatePolicyStatements(statements); err != nil {
+		return err
+	}
+
+	updatePolicyRequestDTO := &UpdatePolicyRequestDTO{
+		Description: description,
+		Statements:  statements,
+	}
+
+	return c.iamcoreClient.UpdatePolicy(ctx, authorizationHeader, policyIRN, updatePolicyRequestDTO)
+}
+
+func (c *client) DeletePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.DeletePolicy(ctx, authorizationHeader, policyIRN)
+}
+
+func (c *client) AttachPolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN,
+	policyIRNs []*irn.IRN,
+) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	principalPath, err := principalTypePath(principalType)
+	if err != nil {
+		return err
+	}
+
+	return c.iamcoreClient.AttachPolicies(ctx, authorizationHeader, principalPath, principalIRN, policyIRNs)
+}
+
+func (c *client) DetachPolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN,
+	policyIRNs []*irn.IRN,
+) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	principalPath, err := principalTypePath(principalType)
+	if err != nil {
+		return err
+	}
+
+	return c.iamcoreClient.DetachPolicies(ctx, authorizationHeader, principalPath, principalIRN, policyIRNs)
+}
+
+func (c *client) ListEffectivePolicies(ctx context.Context, authorizationHeader http.Header, principalType PrincipalType, principalIRN *irn.IRN) (
+	[]*PolicyResponseDTO, error,
+) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	principalPath, err := principalTypePath(principalType)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.iamcoreClient.GetEffectivePolicies(ctx, authorizationHeader, principalPath, principalIRN)
+}
+
+func (f *PolicyFilter) query() url.Values {
+	return listQuery(map[string]string{
+		"name":     f.Name,
+		"tenantID": f.TenantID,
+	}, f.Page, f.PageSize)
+}
+
+func principalTypePath(principalType PrincipalType) (string, error) {
+	switch principalType {
+	case PrincipalTypeUser:
+		return userPath, nil
+	case PrincipalTypeGroup:
+		return groupPath, nil
+	case PrincipalTypeAPIKey:
+		return apiKeyPath, nil
+	default:
+		return "", fmt.Errorf("unknown principal type %q: %w", principalType, ErrBadRequest)
+	}
+}
+
+func validatePolicyStatements(statements []*PolicyStatementDTO) error {
+	for i, statement := range statements {
+		if statement.Effect != PolicyEffectAllow && statement.Effect != PolicyEffectDeny {
+			return fmt.Errorf("statement #%d: unknown effect %q: %w", i, statement.Effect, ErrBadRequest)
+		}
+
+		if len(statement.Actions) == 0 || len(statement.Resources) == 0 {
+			return fmt.Errorf("statement #%d: actions and resources are required: %w", i, ErrBadRequest)
+		}
+	}
+
+	return nil
+}
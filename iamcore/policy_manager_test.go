@@ -0,0 +1,144 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func TestPolicyManager(t *testing.T) {
+	policyIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "policy", nil, "readers")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groupIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "group", nil, "staff")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	userIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "user", nil, "john")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policyURLPath := policyPath + "/" + policyIRN.Base64()
+	groupURLPath := groupPath + "/" + groupIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + policyPath:                                            {http.StatusCreated, `{"data":{"id":"readers","name":"readers"}}`},
+		"GET " + policyPath:                                             {http.StatusOK, `{"data":[{"id":"readers"}],"count":1,"page":2,"pageSize":10}`},
+		"GET " + policyURLPath:                                          {http.StatusOK, `{"data":{"id":"readers","name":"readers"}}`},
+		"PATCH " + policyURLPath:                                        {http.StatusNoContent, ""},
+		"DELETE " + policyURLPath:                                       {http.StatusConflict, `{"message":"policy is attached"}`},
+		"PUT " + groupURLPath + "/policies/attach":                      {http.StatusNoContent, ""},
+		"PUT " + groupURLPath + "/policies/detach":                      {http.StatusForbidden, `{"message":"forbidden"}`},
+		"GET " + groupURLPath + "/policies":                             {http.StatusOK, `{"data":[{"id":"readers"},{"id":"writers"}],"count":2}`},
+		"PUT " + userPath + "/" + userIRN.Base64() + "/policies/attach": {http.StatusNotFound, `{"message":"user not found"}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+	statements := []*PolicyStatementDTO{{Effect: PolicyEffectAllow, Resources: []string{"*"}, Actions: []string{"iamcore:*:read"}}}
+
+	policy, err := c.CreatePolicy(ctx, nil, "acme", "readers", "", statements)
+	if err != nil || policy.ID != "readers" {
+		t.Fatalf("unexpected policy %+v: %v", policy, err)
+	}
+
+	expectedBody := `{"name":"readers","tenantID":"acme","statements":[{"effect":"allow","resources":["*"],"actions":["iamcore:*:read"]}]}`
+	if body := server.lastRequest(t).body; body != expectedBody {
+		t.Fatalf("unexpected create request %s", body)
+	}
+
+	if policy, err = c.GetPolicy(ctx, nil, policyIRN); err != nil || policy.Name != "readers" {
+		t.Fatalf("unexpected policy %+v: %v", policy, err)
+	}
+
+	policies, err := c.ListPolicies(ctx, nil, PolicyFilter{TenantID: "acme", Page: 2, PageSize: 10})
+	if err != nil || len(policies.Data) != 1 || policies.Page != 2 {
+		t.Fatalf("unexpected policies %+v: %v", policies, err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "page=2&pageSize=10&tenantID=acme" {
+		t.Fatalf("unexpected list query %s", query)
+	}
+
+	if err = c.UpdatePolicy(ctx, nil, policyIRN, "read only", statements); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if request := server.lastRequest(t); request.method != http.MethodPatch || request.path != policyURLPath {
+		t.Fatalf("unexpected update request %s %s", request.method, request.path)
+	}
+
+	if err = c.AttachPolicies(ctx, nil, PrincipalTypeGroup, groupIRN, []*irn.IRN{policyIRN}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"policyIDs":["`+policyIRN.Base64()+`"]}` {
+		t.Fatalf("unexpected attach request %s", body)
+	}
+
+	if err = c.DetachPolicies(ctx, nil, PrincipalTypeGroup, groupIRN, []*irn.IRN{policyIRN}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden error, got %v", err)
+	}
+
+	effective, err := c.ListEffectivePolicies(ctx, nil, PrincipalTypeGroup, groupIRN)
+	if err != nil || len(effective) != 2 {
+		t.Fatalf("unexpected effective policies %v: %v", effective, err)
+	}
+
+	if query := server.lastRequest(t).query; query.Get("effective") != "true" || query.Get("page") != "1" {
+		t.Fatalf("unexpected effective policies query %v", query)
+	}
+
+	var apiError *APIError
+	if err = c.DeletePolicy(ctx, nil, policyIRN); !errors.Is(err, ErrConflict) || !errors.As(err, &apiError) || apiError.Message != "policy is attached" {
+		t.Fatalf("expected ErrConflict API error, got %v", err)
+	}
+
+	if err = c.AttachPolicies(ctx, nil, PrincipalTypeUser, userIRN, []*irn.IRN{policyIRN}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound error, got %v", err)
+	}
+}
+
+func TestPolicyManagerValidation(t *testing.T) {
+	server := newStubServer(t, map[string]stubResponse{})
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	principalIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "user", nil, "john")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, statement := range []*PolicyStatementDTO{
+		{Effect: "permit", Resources: []string{"*"}, Actions: []string{"*"}},
+		{Effect: PolicyEffectDeny, Resources: []string{"*"}},
+	} {
+		if _, err = c.CreatePolicy(ctx, nil, "", "invalid", "", []*PolicyStatementDTO{statement}); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("expected ErrBadRequest error for %+v, got %v", statement, err)
+		}
+	}
+
+	if err = c.AttachPolicies(ctx, nil, "robot", principalIRN, nil); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest error, got %v", err)
+	}
+}
+
+func TestPolicyFilterQuery(t *testing.T) {
+	for filter, expected := range map[PolicyFilter]string{
+		{}:                                 "",
+		{Name: "readers & writers"}:        "name=readers+%26+writers",
+		{TenantID: "acme", PageSize: 50}:   "pageSize=50&tenantID=acme",
+		{Page: -1, PageSize: 0, Name: "x"}: "name=x",
+	} {
+		if query := filter.query().Encode(); query != expected {
+			t.Errorf("%+v: expected query %q, got %q", filter, expected, query)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package iamcore
+
+import (
+	"context"
+	"net/http"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+type PoolManager interface {
+	// CreatePool creates pool of resources on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated pool found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreatePool(ctx context.Context, authorizationHeader http.Header, tenantID, name string, resourceIDs []string) (*PoolResponseDTO, error)
+
+	// RenamePool renames pool on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case pool does not exist.
+	// Returns ErrConflict error in case pool with the new name already exists.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	RenamePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, name string) error
+
+	// DeletePool deletes pool on iamcore. The pool resources are not deleted.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case pool does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to delete the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	DeletePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) error
+
+	// AddResourcesToPool attaches resources to pool on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case pool or any of resources does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	AddResourcesToPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error
+
+	// RemoveResourcesFromPool detaches resources from pool on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case pool does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	RemoveResourcesFromPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error
+
+	// ListPoolResources retrieves resources attached to pool from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case pool does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the pool.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListPoolResources(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) ([]*ResourceResponseDTO, error)
+}
+
+func (c *client) CreatePool(ctx context.Context, authorizationHeader http.Header, tenantID, name string, resourceIDs []string) (*PoolResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	createPoolRequestDTO := &CreatePoolRequestDTO{
+		Name:        name,
+		TenantID:    tenantID,
+		ResourceIDs: dropEmptyStrings(resourceIDs),
+	}
+
+	return c.iamcoreClient.CreatePool(ctx, authorizationHeader, createPoolRequestDTO)
+}
+
+func (c *client) RenamePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, name string) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.UpdatePool(ctx, authorizationHeader, poolIRN, &UpdatePoolRequestDTO{Name: name})
+}
+
+func (c *client) DeletePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.DeletePool(ctx, authorizationHeader, poolIRN)
+}
+
+func (c *client) AddResourcesToPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.AttachResourcesToPool(ctx, authorizationHeader, poolIRN, dropEmptyStrings(resourceIDs))
+}
+
+func (c *client) RemoveResourcesFromPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.DetachResourcesFromPool(ctx, authorizationHeader, poolIRN, dropEmptyStrings(resourceIDs))
+}
+
+func (c *client) ListPoolResources(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) ([]*ResourceResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetPoolResources(ctx, authorizationHeader, poolIRN)
+}
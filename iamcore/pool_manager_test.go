@@ -0,0 +1,74 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func TestPoolManager(t *testing.T) {
+	poolIRN, err := irn.NewIRN("rc73dbh7q0", "iamcore", "acme", nil, "pool", nil, "lab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	poolURLPath := poolPath + "/" + poolIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + poolPath:                         {http.StatusCreated, `{"data":{"id":"lab","name":"lab","resourceIDs":["d1"]}}`},
+		"PATCH " + poolURLPath:                     {http.StatusNoContent, ""},
+		"DELETE " + poolURLPath:                    {http.StatusNotFound, `{"message":"pool not found"}`},
+		"PUT " + poolURLPath + "/resources/attach": {http.StatusNoContent, ""},
+		"PUT " + poolURLPath + "/resources/detach": {http.StatusNoContent, ""},
+		"GET " + poolURLPath + "/resources":        {http.StatusOK, `{"data":[{"id":"d1"}],"count":1}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	pool, err := c.CreatePool(ctx, nil, "acme", "lab", []string{"d1", ""})
+	if err != nil || pool.ID != "lab" || len(pool.ResourceIDs) != 1 {
+		t.Fatalf("unexpected pool %+v: %v", pool, err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"name":"lab","tenantID":"acme","resourceIDs":["d1"]}` {
+		t.Fatalf("unexpected create request %s", body)
+	}
+
+	if err = c.RenamePool(ctx, nil, poolIRN, "laboratory"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"name":"laboratory"}` {
+		t.Fatalf("unexpected rename request %s", body)
+	}
+
+	if err = c.AddResourcesToPool(ctx, nil, poolIRN, []string{"d2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = c.RemoveResourcesFromPool(ctx, nil, poolIRN, []string{"d1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if request := server.lastRequest(t); request.path != poolURLPath+"/resources/detach" || request.body != `{"resourceIDs":["d1"]}` {
+		t.Fatalf("unexpected detach request %s %s", request.path, request.body)
+	}
+
+	resources, err := c.ListPoolResources(ctx, nil, poolIRN)
+	if err != nil || len(resources) != 1 || resources[0].ID != "d1" {
+		t.Fatalf("unexpected pool resources %v: %v", resources, err)
+	}
+
+	if query := server.lastRequest(t).query; query.Get("page") != "1" || query.Get("pageSize") == "" {
+		t.Fatalf("unexpected list query %v", query)
+	}
+
+	var apiError *APIError
+	if err = c.DeletePool(ctx, nil, poolIRN); !errors.Is(err, ErrNotFound) || !errors.As(err, &apiError) || apiError.Message != "pool not found" {
+		t.Fatalf("expected ErrNotFound API error, got %v", err)
+	}
+}
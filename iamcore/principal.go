@@ -0,0 +1,84 @@
+package iamcore
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// PrincipalKind describes what kind of caller the principal is.
+type PrincipalKind string
+
+const (
+	// PrincipalKindUser is a user authenticated by means of OAuth 2.0 Access Token.
+	PrincipalKindUser PrincipalKind = "user"
+	// PrincipalKindServiceAccount is a principal authenticated by means of "X-iamcore-API-Key" HTTP header,
+	// OAuth 2.0 Access Token issued to other than a user, e.g. by client credentials grant, or client certificate.
+	PrincipalKindServiceAccount PrincipalKind = "service-account"
+	// PrincipalKindAnonymous is a principal of a request that carries no credentials.
+	PrincipalKindAnonymous PrincipalKind = "anonymous"
+)
+
+// Principal is the authenticated requester stored in the request context by WithAuth.
+type Principal struct {
+	// IRN of the principal as resolved by iamcore.
+	IRN *irn.IRN
+	// Kind of the principal.
+	Kind PrincipalKind
+	// Authenticator is the name of the authenticator that established the principal.
+	Authenticator string
+	// ExpiresAt is the credential expiry; zero if the credential does not expire or the expiry is unknown.
+	ExpiresAt time.Time
+	// Claims are the raw JWT claims of the access token; nil unless authenticated with a JWT bearer token.
+	Claims map[string]interface{}
+}
+
+// IsServiceAccount reports whether the principal is a service account.
+func (p *Principal) IsServiceAccount() bool {
+	return p.Kind == PrincipalKindServiceAccount
+}
+
+// IsAnonymous reports whether the principal is anonymous.
+func (p *Principal) IsAnonymous() bool {
+	return p.Kind == PrincipalKindAnonymous
+}
+
+// PrincipalFromContext extracts and returns principal from the request context.
+func PrincipalFromContext(ctx context.Context) (*Principal, error) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	if !ok {
+		return nil, ErrNoAuthContext
+	}
+
+	return principal, nil
+}
+
+// IsServiceAccount reports whether principal from the request context is a service account.
+func IsServiceAccount(ctx context.Context) (bool, error) {
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return principal.IsServiceAccount(), nil
+}
+
+// IsAnonymous reports whether principal from the request context is anonymous.
+func IsAnonymous(ctx context.Context) (bool, error) {
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return principal.IsAnonymous(), nil
+}
+
+// withPrincipal returns a copy of the context carrying principal and its authorization header.
+func withPrincipal(ctx context.Context, principal *Principal, authorizationHeader http.Header) context.Context {
+	ctx = context.WithValue(ctx, principalKey, principal)
+	ctx = context.WithValue(ctx, principalAuthorizationHeaderKey, authorizationHeader)
+
+	return ctx
+}
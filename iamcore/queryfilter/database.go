@@ -0,0 +1,77 @@
+package queryfilter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Placeholder marks the place of authorization condition in the query passed to Apply and QueryContext, e.g.
+//
+//	SELECT id, name FROM devices WHERE tenant_id = $1 AND {authz} ORDER BY name LIMIT 50
+const Placeholder = "{authz}"
+
+var (
+	ErrNoPlaceholder     = errors.New("query must contain exactly one " + Placeholder + " placeholder")
+	ErrArgumentsMismatch = errors.New("query placeholders do not match arguments")
+)
+
+// Queryer is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Apply replaces the Placeholder in the query with the filter condition and returns the query arguments
+// including the condition ones.
+//
+// With numbered placeholders, like "$n" of PostgreSQL, the condition placeholders are numbered after args and
+// the condition arguments are appended to args. With positional placeholders, like "?" of MySQL, the condition
+// arguments are inserted after the args bound to "?" preceding the Placeholder, so the query may use its own
+// arguments both before and after it. The "?" are counted as is, so the query must not contain "?" in literals
+// or comments before the Placeholder.
+//
+// Returns ErrNoPlaceholder error in case the query has no or several placeholders.
+// Returns ErrArgumentsMismatch error in case the query has more "?" before the Placeholder than args.
+func Apply(dialect Dialect, filter *Filter, columns Columns, query string, args ...interface{}) (string, []interface{}, error) {
+	if strings.Count(query, Placeholder) != 1 {
+		return "", nil, ErrNoPlaceholder
+	}
+
+	before := strings.Index(query, Placeholder)
+	argOffset := len(args)
+
+	// Positional placeholders are bound in order of appearance.
+	if dialect.Placeholder(1) == dialect.Placeholder(2) {
+		argOffset = strings.Count(query[:before], dialect.Placeholder(1))
+
+		if argOffset > len(args) {
+			return "", nil, fmt.Errorf("%d placeholders before %s, %d arguments: %w", argOffset, Placeholder, len(args), ErrArgumentsMismatch)
+		}
+	}
+
+	clause, filterArgs, err := filter.SQL(dialect, columns, argOffset)
+	if err != nil {
+		return "", nil, fmt.Errorf("render authorization filter: %w", err)
+	}
+
+	allArgs := make([]interface{}, 0, len(args)+len(filterArgs))
+	allArgs = append(allArgs, args[:argOffset]...)
+	allArgs = append(allArgs, filterArgs...)
+	allArgs = append(allArgs, args[argOffset:]...)
+
+	return query[:before] + clause + query[before+len(Placeholder):], allArgs, nil
+}
+
+// QueryContext executes the query restricted by the filter; see Apply.
+func QueryContext(ctx context.Context, queryer Queryer, dialect Dialect, filter *Filter, columns Columns, query string, args ...interface{}) (
+	*sql.Rows, error,
+) {
+	query, args, err := Apply(dialect, filter, columns, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryer.QueryContext(ctx, query, args...)
+}
@@ -0,0 +1,49 @@
+// Package queryfilter provides typed authorization filters over resources and renders them
+// into database specific conditions, so that only resources the principal is authorized to
+// can be selected from the application database.
+package queryfilter
+
+// Filter is the set of resources the principal is granted an action on.
+//
+// A resource matches the filter if the filter is unrestricted, or the resource matches any of IRN prefixes,
+// resource IDs or paths; in either case provided the resource ID is not excluded.
+// The filter without any condition matches no resources.
+type Filter struct {
+	// Unrestricted is set in case the principal is granted the action on all the resources.
+	Unrestricted bool `json:"unrestricted"`
+	// IRNPrefixes match resources which IRN starts with any of the prefixes, as granted by wildcard policies.
+	IRNPrefixes []string `json:"irnPrefixes,omitempty"`
+	// ResourceIDs match resources having any of the IDs.
+	ResourceIDs []string `json:"resourceIDs,omitempty"`
+	// Paths match resources located at any of the paths.
+	Paths []*Path `json:"paths,omitempty"`
+	// ExcludedResourceIDs never match, as explicitly denied.
+	ExcludedResourceIDs []string `json:"excludedResourceIDs,omitempty"`
+}
+
+// Path matches resources located at the path.
+type Path struct {
+	Path string `json:"path"`
+	// Recursive matches also resources located at nested paths.
+	Recursive bool `json:"recursive"`
+}
+
+// Columns names the columns (or document fields) of the resource storage the filter is applied to.
+// Conditions on unnamed columns are not rendered, so the corresponding filter conditions must be empty.
+type Columns struct {
+	IRN        string
+	ResourceID string
+	Path       string
+}
+
+// DefaultColumns are "irn", "resource_id" and "path" columns.
+var DefaultColumns = Columns{
+	IRN:        "irn",
+	ResourceID: "resource_id",
+	Path:       "path",
+}
+
+// MatchesNothing reports whether the filter matches no resources.
+func (f *Filter) MatchesNothing() bool {
+	return !f.Unrestricted && len(f.IRNPrefixes) == 0 && len(f.ResourceIDs) == 0 && len(f.Paths) == 0
+}
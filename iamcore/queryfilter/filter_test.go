@@ -0,0 +1,129 @@
+package queryfilter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPostgreSQL(t *testing.T) {
+	filter := &Filter{
+		IRNPrefixes:         []string{"irn:rc73dbh7q0:fleet:acme:device/eu_west/"},
+		ResourceIDs:         []string{"d1", "d2"},
+		Paths:               []*Path{{Path: "/lab", Recursive: true}},
+		ExcludedResourceIDs: []string{"d3"},
+	}
+
+	query, args, err := Apply(PostgreSQL, filter, DefaultColumns, "SELECT id FROM devices WHERE tenant_id = $1 AND {authz} LIMIT 10", "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantQuery := `SELECT id FROM devices WHERE tenant_id = $1 AND ((irn LIKE $2 ESCAPE '!' OR resource_id IN ($3, $4) OR ` +
+		`path = $5 OR path LIKE $6 ESCAPE '!') AND resource_id NOT IN ($7)) LIMIT 10`
+	if query != wantQuery {
+		t.Fatalf("unexpected query:\n%s\nwant:\n%s", query, wantQuery)
+	}
+
+	wantArgs := []interface{}{"acme", `irn:rc73dbh7q0:fleet:acme:device/eu!_west/%`, "d1", "d2", "/lab", "/lab/%", "d3"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("unexpected args %v, want %v", args, wantArgs)
+	}
+}
+
+func TestSQLMySQLAndEdgeCases(t *testing.T) {
+	clause, args, err := (&Filter{ResourceIDs: []string{"d1"}}).SQL(MySQL, DefaultColumns, 3)
+	if err != nil || clause != "(resource_id IN (?))" || len(args) != 1 {
+		t.Fatalf("unexpected MySQL rendering: %q %v %v", clause, args, err)
+	}
+
+	clause, args, err = (&Filter{Paths: []*Path{{Path: `/a\b!c%`, Recursive: true}}}).SQL(MySQL, DefaultColumns, 0)
+	if err != nil || clause != "(path = ? OR path LIKE ? ESCAPE '!')" || args[1] != `/a\b!!c!%/%` {
+		t.Fatalf("unexpected MySQL LIKE rendering: %q %v %v", clause, args, err)
+	}
+
+	if clause, _, _ = (&Filter{}).SQL(MySQL, DefaultColumns, 0); clause != "(1 = 0)" {
+		t.Fatalf("expected empty filter to match nothing, got %q", clause)
+	}
+
+	if clause, _, _ = (&Filter{Unrestricted: true}).SQL(MySQL, DefaultColumns, 0); clause != "(1 = 1)" {
+		t.Fatalf("expected unrestricted filter to match everything, got %q", clause)
+	}
+
+	if _, _, err = Apply(MySQL, &Filter{}, DefaultColumns, "SELECT 1"); err != ErrNoPlaceholder {
+		t.Fatalf("expected ErrNoPlaceholder, got %v", err)
+	}
+}
+
+func TestApplyMySQLPlaceholderBeforeQueryArguments(t *testing.T) {
+	filter := &Filter{ResourceIDs: []string{"d1", "d2"}}
+
+	query, args, err := Apply(MySQL, filter, DefaultColumns, "SELECT id FROM devices WHERE tenant_id = ? AND {authz} AND name LIKE ? LIMIT ?", "acme", "lab%", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantQuery := "SELECT id FROM devices WHERE tenant_id = ? AND (resource_id IN (?, ?)) AND name LIKE ? LIMIT ?"
+	if query != wantQuery {
+		t.Fatalf("unexpected query:\n%s\nwant:\n%s", query, wantQuery)
+	}
+
+	wantArgs := []interface{}{"acme", "d1", "d2", "lab%", 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("unexpected args %v, want %v", args, wantArgs)
+	}
+
+	if _, _, err = Apply(MySQL, filter, DefaultColumns, "SELECT id FROM devices WHERE tenant_id = ? AND {authz}"); !errors.Is(err, ErrArgumentsMismatch) {
+		t.Fatalf("expected ErrArgumentsMismatch, got %v", err)
+	}
+}
+
+func TestSQLUnrestrictedWithExclusions(t *testing.T) {
+	filter := &Filter{Unrestricted: true, ExcludedResourceIDs: []string{"d3", "d4"}}
+
+	clause, args, err := filter.SQL(PostgreSQL, DefaultColumns, 1)
+	if err != nil || clause != "(resource_id NOT IN ($2, $3))" || !reflect.DeepEqual(args, []interface{}{"d3", "d4"}) {
+		t.Fatalf("unexpected PostgreSQL rendering: %q %v %v", clause, args, err)
+	}
+
+	clause, args, err = filter.SQL(MySQL, DefaultColumns, 0)
+	if err != nil || clause != "(resource_id NOT IN (?, ?))" || len(args) != 2 {
+		t.Fatalf("unexpected MySQL rendering: %q %v %v", clause, args, err)
+	}
+
+	if _, _, err = filter.SQL(PostgreSQL, Columns{IRN: "irn"}, 0); !errors.Is(err, ErrMissingColumn) {
+		t.Fatalf("expected ErrMissingColumn, got %v", err)
+	}
+}
+
+func TestMongoUnrestrictedWithExclusions(t *testing.T) {
+	query, err := (&Filter{Unrestricted: true, ExcludedResourceIDs: []string{"d3"}}).Mongo(DefaultColumns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{"resource_id": map[string]interface{}{"$nin": []string{"d3"}}}
+	if !reflect.DeepEqual(query, want) {
+		t.Fatalf("unexpected query %v, want %v", query, want)
+	}
+
+	if query, _ = (&Filter{Unrestricted: true}).Mongo(DefaultColumns); len(query) != 0 {
+		t.Fatalf("expected unrestricted filter to match everything, got %v", query)
+		t.Fatalf("expected ErrNoPlaceholder, got %v", err)
+	}
+}
+
+func TestMongo(t *testing.T) {
+	query, err := (&Filter{ResourceIDs: []string{"d1"}, Paths: []*Path{{Path: "/lab"}}}).Mongo(DefaultColumns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{"$or": []interface{}{
+		map[string]interface{}{"resource_id": map[string]interface{}{"$in": []string{"d1"}}},
+		map[string]interface{}{"path": "/lab"},
+	}}
+	if !reflect.DeepEqual(query, want) {
+		t.Fatalf("unexpected query %v, want %v", query, want)
+	}
+}
@@ -0,0 +1,78 @@
+package queryfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mongo renders the filter as MongoDB query document; the result is assignable to bson.M.
+func (f *Filter) Mongo(fields Columns) (map[string]interface{}, error) {
+	if f.Unrestricted {
+		if len(f.ExcludedResourceIDs) == 0 {
+			return map[string]interface{}{}, nil
+		}
+
+		if fields.ResourceID == "" {
+			return nil, fmt.Errorf("excluded resource IDs: %w", ErrMissingColumn)
+		}
+
+		// Explicit deny takes precedence over the grant on all the resources.
+		return map[string]interface{}{fields.ResourceID: map[string]interface{}{"$nin": f.ExcludedResourceIDs}}, nil
+	}
+
+	if f.MatchesNothing() {
+		// Negation of the empty document, which matches everything.
+		return map[string]interface{}{"$nor": []interface{}{map[string]interface{}{}}}, nil
+	}
+
+	conditions := make([]interface{}, 0)
+
+	if len(f.IRNPrefixes) != 0 {
+		if fields.IRN == "" {
+			return nil, fmt.Errorf("IRN prefixes: %w", ErrMissingColumn)
+		}
+
+		for _, prefix := range f.IRNPrefixes {
+			conditions = append(conditions, map[string]interface{}{fields.IRN: map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(prefix)}})
+		}
+	}
+
+	if len(f.ResourceIDs) != 0 {
+		if fields.ResourceID == "" {
+			return nil, fmt.Errorf("resource IDs: %w", ErrMissingColumn)
+		}
+
+		conditions = append(conditions, map[string]interface{}{fields.ResourceID: map[string]interface{}{"$in": f.ResourceIDs}})
+	}
+
+	if len(f.Paths) != 0 {
+		if fields.Path == "" {
+			return nil, fmt.Errorf("paths: %w", ErrMissingColumn)
+		}
+
+		for _, path := range f.Paths {
+			conditions = append(conditions, map[string]interface{}{fields.Path: path.Path})
+
+			if path.Recursive {
+				nested := "^" + regexp.QuoteMeta(strings.TrimSuffix(path.Path, "/")+"/")
+				conditions = append(conditions, map[string]interface{}{fields.Path: map[string]interface{}{"$regex": nested}})
+			}
+		}
+	}
+
+	query := map[string]interface{}{"$or": conditions}
+
+	if len(f.ExcludedResourceIDs) != 0 {
+		if fields.ResourceID == "" {
+			return nil, fmt.Errorf("excluded resource IDs: %w", ErrMissingColumn)
+		}
+
+		query = map[string]interface{}{"$and": []interface{}{
+			query,
+			map[string]interface{}{fields.ResourceID: map[string]interface{}{"$nin": f.ExcludedResourceIDs}},
+		}}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,143 @@
+package queryfilter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrMissingColumn = errors.New("filter condition on unnamed column")
+
+// Dialect renders SQL placeholders and LIKE escaping of a database engine.
+type Dialect interface {
+	// Placeholder returns placeholder of n-th query argument, starting from 1.
+	// Positional placeholders, like "?" of MySQL, are the same for any n.
+	Placeholder(n int) string
+	// LikeEscape returns the ESCAPE clause for "!" as LIKE escape character. Unlike backslash, "!" is
+	// not special in string literals, e.g. under NO_BACKSLASH_ESCAPES mode of MySQL.
+	LikeEscape() string
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (postgresDialect) LikeEscape() string {
+	return "ESCAPE '!'"
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) LikeEscape() string {
+	return "ESCAPE '!'"
+}
+
+var (
+	// PostgreSQL dialect with "$n" placeholders.
+	PostgreSQL Dialect = postgresDialect{}
+	// MySQL dialect with "?" placeholders.
+	MySQL Dialect = mysqlDialect{}
+)
+
+// SQL renders the filter as parenthesized SQL condition for WHERE clause. Placeholders are numbered
+// after argOffset arguments already used by the query; the returned arguments are to be appended to them.
+// Column names are rendered as is and must be trusted identifiers.
+func (f *Filter) SQL(dialect Dialect, columns Columns, argOffset int) (string, []interface{}, error) {
+	r := &sqlRenderer{dialect: dialect, argOffset: argOffset}
+
+	if f.Unrestricted {
+		if len(f.ExcludedResourceIDs) == 0 {
+			return "(1 = 1)", nil, nil
+		}
+
+		if columns.ResourceID == "" {
+			return "", nil, fmt.Errorf("excluded resource IDs: %w", ErrMissingColumn)
+		}
+
+		// Explicit deny takes precedence over the grant on all the resources.
+		return fmt.Sprintf("(%s NOT IN (%s))", columns.ResourceID, r.args(f.ExcludedResourceIDs)), r.values, nil
+	}
+
+	if f.MatchesNothing() {
+		return "(1 = 0)", nil, nil
+	}
+
+	conditions := make([]string, 0)
+
+	if len(f.IRNPrefixes) != 0 {
+		if columns.IRN == "" {
+			return "", nil, fmt.Errorf("IRN prefixes: %w", ErrMissingColumn)
+		}
+
+		for _, prefix := range f.IRNPrefixes {
+			conditions = append(conditions, fmt.Sprintf("%s LIKE %s %s", columns.IRN, r.arg(escapeLike(prefix)+"%"), dialect.LikeEscape()))
+		}
+	}
+
+	if len(f.ResourceIDs) != 0 {
+		if columns.ResourceID == "" {
+			return "", nil, fmt.Errorf("resource IDs: %w", ErrMissingColumn)
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", columns.ResourceID, r.args(f.ResourceIDs)))
+	}
+
+	if len(f.Paths) != 0 {
+		if columns.Path == "" {
+			return "", nil, fmt.Errorf("paths: %w", ErrMissingColumn)
+		}
+
+		for _, path := range f.Paths {
+			conditions = append(conditions, fmt.Sprintf("%s = %s", columns.Path, r.arg(path.Path)))
+
+			if path.Recursive {
+				nested := escapeLike(strings.TrimSuffix(path.Path, "/")) + "/%"
+				conditions = append(conditions, fmt.Sprintf("%s LIKE %s %s", columns.Path, r.arg(nested), dialect.LikeEscape()))
+			}
+		}
+	}
+
+	clause := "(" + strings.Join(conditions, " OR ") + ")"
+
+	if len(f.ExcludedResourceIDs) != 0 {
+		if columns.ResourceID == "" {
+			return "", nil, fmt.Errorf("excluded resource IDs: %w", ErrMissingColumn)
+		}
+
+		clause = fmt.Sprintf("(%s AND %s NOT IN (%s))", clause, columns.ResourceID, r.args(f.ExcludedResourceIDs))
+	}
+
+	return clause, r.values, nil
+}
+
+type sqlRenderer struct {
+	dialect   Dialect
+	argOffset int
+	values    []interface{}
+}
+
+func (r *sqlRenderer) arg(value string) string {
+	r.values = append(r.values, value)
+
+	return r.dialect.Placeholder(r.argOffset + len(r.values))
+}
+
+func (r *sqlRenderer) args(values []string) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = r.arg(value)
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// escapeLike escapes LIKE wildcards with "!", see Dialect.LikeEscape.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(value)
+}
@@ -3,6 +3,7 @@ package iamcore
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
 )
@@ -37,6 +38,62 @@ type ResourceManager interface {
 	// Returns ErrUnknown error in case of unexpected response from iamcore server.
 	DeleteResource(ctx context.Context, authorizationHeader http.Header, application, tenantID, resourceType, resourcePath, resourceID string) error
 
+	// GetResource retrieves resource from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case resource does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the resource.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	GetResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN) (*ResourceResponseDTO, error)
+
+	// ListResources retrieves a page of resources matching the filter from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read resources.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListResources(ctx context.Context, authorizationHeader http.Header, filter ResourceFilter) (*ResourcesResponseDTO, error)
+
+	// UpdateResource updates resource on iamcore: renames it, moves it to another path or changes its pools membership.
+	// Only non-nil fields of the update are changed.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case resource does not exist.
+	// Returns ErrConflict error in case resource with the new name or path already exists.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the resource.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	UpdateResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN, update *UpdateResourceRequestDTO) error
+
+	// SetResourceEnabled enables or disables resource on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case resource does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update the resource.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	SetResourceEnabled(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN, enabled bool) error
+
+	// CreateResources creates resources on iamcore with bounded concurrency and reports the outcome per resource.
+	// Already existing resources are reported with BulkStatusConflict and count as succeeded if BulkOptions.Idempotent is set.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Failures of individual resources are reported in BulkReport rather than returned.
+	CreateResources(ctx context.Context, authorizationHeader http.Header, resources []ResourceDescriptor, options BulkOptions) (*BulkReport, error)
+
+	// DeleteResources deletes resources on iamcore with bounded concurrency and reports the outcome per resource.
+	// Missing resources are reported with BulkStatusNotFound and count as succeeded if BulkOptions.Idempotent is set.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Failures of individual resources are reported in BulkReport rather than returned.
+	DeleteResources(ctx context.Context, authorizationHeader http.Header, resources []ResourceDescriptor, options BulkOptions) (*BulkReport, error)
+
 	// CreateResourceType creates a new resource type for application on iamcore.
 	//
 	// Returns ErrSDKDisabled error in case SDK is disabled.
@@ -46,6 +103,62 @@ type ResourceManager interface {
 	// Returns ErrUnknown error in case of unexpected response from iamcore server.
 	CreateResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType, actionPrefix string, operations []string) error
 
+	// CreateResourceTypeWithDescription creates a new resource type having description for application on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case resource type already exists.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create resource type.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreateResourceTypeWithDescription(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType, description,
+		actionPrefix string, operations []string) error
+
+	// UpdateResourceType updates description, action prefix and operations of application's resource type on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case resource type does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to update resource type.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	UpdateResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN,
+		description, actionPrefix string, operations []string) error
+
+	// DeleteResourceType deletes application's resource type on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case resource type does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to delete resource type.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	DeleteResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN) error
+
+	// EnsureResourceType makes sure application's resource type exists on iamcore with the given action prefix and operations:
+	// creates it if missing, updates it if it differs, and reports what changed. The resource type created concurrently,
+	// e.g. by another replica, is reconciled the same way as the existing one.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to manage resource types.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	EnsureResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType, actionPrefix string,
+		operations []string) (*EnsureResult, error)
+
+	// EnsureResource makes sure resource exists on iamcore and is attached to exactly the given pools:
+	// creates it if missing, updates its pools if they differ, and reports what changed.
+	// Pools membership of the existing resource is not changed if poolIDs is nil.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to manage the resource.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	EnsureResource(ctx context.Context, authorizationHeader http.Header, accountID, application, tenantID, resourceType, resourcePath, resourceID string,
+		poolIDs []string) (*EnsureResult, error)
+
 	// GetResourceTypes retrieves application`s resource types on iamcore.
 	//
 	// Returns ErrSDKDisabled error in case SDK is disabled.
@@ -57,6 +170,8 @@ type ResourceManager interface {
 
 	// AttachUserToPolicy attaches authenticated principal to policy.
 	//
+	// Deprecated: the policy IRN is built from resourceType argument; use AttachPolicies with the policy IRN instead.
+	//
 	// Returns ErrSDKDisabled error in case SDK is disabled.
 	// Returns ErrUnauthenticated error in case of unauthenticated access.
 	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read resource types.
@@ -140,6 +255,38 @@ func (c *client) DeleteResource(ctx context.Context, authorizationHeader http.He
 	return c.iamcoreClient.DeleteResource(ctx, authorizationHeader, resourceIRN)
 }
 
+func (c *client) GetResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN) (*ResourceResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetResource(ctx, authorizationHeader, resourceIRN)
+}
+
+func (c *client) ListResources(ctx context.Context, authorizationHeader http.Header, filter ResourceFilter) (*ResourcesResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetResources(ctx, authorizationHeader, filter.query())
+}
+
+func (c *client) UpdateResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN, update *UpdateResourceRequestDTO) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.UpdateResource(ctx, authorizationHeader, resourceIRN, update)
+}
+
+func (c *client) SetResourceEnabled(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN, enabled bool) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.UpdateResource(ctx, authorizationHeader, resourceIRN, &UpdateResourceRequestDTO{Enabled: &enabled})
+}
+
 func (c *client) AttachUserToPolicy(ctx context.Context, authorizationHeader http.Header, application, tenantID, resourceType, policyID string, userIRN *irn.IRN) error {
 	if c.disabled {
 		return ErrSDKDisabled
@@ -155,6 +302,12 @@ func (c *client) AttachUserToPolicy(ctx context.Context, authorizationHeader htt
 
 func (c *client) CreateResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType,
 	actionPrefix string, operations []string,
+) error {
+	return c.CreateResourceTypeWithDescription(ctx, authorizationHeader, accountID, application, resourceType, "", actionPrefix, operations)
+}
+
+func (c *client) CreateResourceTypeWithDescription(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType,
+	description, actionPrefix string, operations []string,
 ) error {
 	if c.disabled {
 		return ErrSDKDisabled
@@ -167,6 +320,7 @@ func (c *client) CreateResourceType(ctx context.Context, authorizationHeader htt
 
 	requestDTO := &CreateResourceTypeRequestDTO{
 		Type:         resourceType,
+		Description:  description,
 		ActionPrefix: actionPrefix,
 		Operations:   dropEmptyStrings(operations),
 	}
@@ -174,6 +328,40 @@ func (c *client) CreateResourceType(ctx context.Context, authorizationHeader htt
 	return c.iamcoreClient.CreateResourceType(ctx, authorizationHeader, applicationIRN, requestDTO)
 }
 
+func (c *client) UpdateResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN,
+	description, actionPrefix string, operations []string,
+) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	applicationIRN, err := irn.NewIRN(accountID, "iamcore", "", nil, "application", nil, application)
+	if err != nil {
+		return err
+	}
+
+	requestDTO := &UpdateResourceTypeRequestDTO{
+		Description:  description,
+		ActionPrefix: actionPrefix,
+		Operations:   dropEmptyStrings(operations),
+	}
+
+	return c.iamcoreClient.UpdateResourceType(ctx, authorizationHeader, applicationIRN, resourceTypeIRN, requestDTO)
+}
+
+func (c *client) DeleteResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	applicationIRN, err := irn.NewIRN(accountID, "iamcore", "", nil, "application", nil, application)
+	if err != nil {
+		return err
+	}
+
+	return c.iamcoreClient.DeleteResourceType(ctx, authorizationHeader, applicationIRN, resourceTypeIRN)
+}
+
 func (c *client) GetResourceTypes(ctx context.Context, authorizationHeader http.Header, accountID, application string) ([]*ResourceTypeResponseDTO, error) {
 	if c.disabled {
 		return nil, ErrSDKDisabled
@@ -205,6 +393,28 @@ func (c *client) GetPoolIDs(ctx context.Context, authorizationHeader http.Header
 	return poolIDs, nil
 }
 
+// ResourceFilter filters resources listed by ListResources. Empty fields are not applied.
+type ResourceFilter struct {
+	Application  string
+	TenantID     string
+	ResourceType string
+	// PathPrefix matches resources having the path or nested paths.
+	PathPrefix string
+	// Page number, starting from 1; the first page by default.
+	Page int
+	// PageSize is the maximum number of resources in the page; iamcore default if zero.
+	PageSize int
+}
+
+func (f *ResourceFilter) query() url.Values {
+	return listQuery(map[string]string{
+		"application":  f.Application,
+		"tenantID":     f.TenantID,
+		"resourceType": f.ResourceType,
+		"path":         f.PathPrefix,
+	}, f.Page, f.PageSize)
+}
+
 // dropEmptyStrings filters a slice to only non-empty strings.
 func dropEmptyStrings(in []string) []string {
 	out := make([]string, 0)
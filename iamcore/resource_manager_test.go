@@ -0,0 +1,107 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+func TestResourceManagerResources(t *testing.T) {
+	resourceIRN, err := irn.NewIRN("rc73dbh7q0", "myapp", "acme", nil, "device", []string{"lab"}, "d1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resourceURLPath := resourcePath + "/" + resourceIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + resourceURLPath:   {http.StatusOK, `{"data":{"id":"d1","name":"thermometer","path":"/lab","enabled":true,"poolIDs":["p1"]}}`},
+		"GET " + resourcePath:      {http.StatusOK, `{"data":[{"id":"d1"},{"id":"d2"}],"count":12,"page":2,"pageSize":2}`},
+		"PATCH " + resourceURLPath: {http.StatusNoContent, ""},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	resource, err := c.GetResource(ctx, nil, resourceIRN)
+	if err != nil || resource.Name != "thermometer" || !resource.Enabled || len(resource.PoolIDs) != 1 {
+		t.Fatalf("unexpected resource %+v: %v", resource, err)
+	}
+
+	resources, err := c.ListResources(ctx, nil, ResourceFilter{Application: "myapp", PathPrefix: "/lab", Page: 2, PageSize: 2})
+	if err != nil || len(resources.Data) != 2 || resources.Count != 12 {
+		t.Fatalf("unexpected resources %+v: %v", resources, err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "application=myapp&page=2&pageSize=2&path=%2Flab" {
+		t.Fatalf("unexpected list query %s", query)
+	}
+
+	name := "hygrometer"
+	noPools := []string{}
+
+	for update, expected := range map[*UpdateResourceRequestDTO]string{
+		{Name: &name}:       `{"name":"hygrometer"}`,
+		{PoolIDs: &noPools}: `{"poolIDs":[]}`,
+		{}:                  `{}`,
+	} {
+		if err = c.UpdateResource(ctx, nil, resourceIRN, update); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if request := server.lastRequest(t); request.method != http.MethodPatch || request.body != expected {
+			t.Errorf("expected update request %s, got %s %s", expected, request.method, request.body)
+		}
+	}
+
+	if err = c.SetResourceEnabled(ctx, nil, resourceIRN, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"enabled":false}` {
+		t.Fatalf("expected disabled flag sent, got %s", body)
+	}
+}
+
+func TestResourceManagerResourceErrors(t *testing.T) {
+	resourceIRN, err := irn.NewIRN("rc73dbh7q0", "myapp", "acme", nil, "device", nil, "d1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resourceURLPath := resourcePath + "/" + resourceIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + resourceURLPath:   {http.StatusNotFound, `{"message":"resource not found"}`},
+		"GET " + resourcePath:      {http.StatusForbidden, `{"message":"forbidden"}`},
+		"PATCH " + resourceURLPath: {http.StatusConflict, `{"message":"resource already exists"}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	if _, err = c.GetResource(ctx, nil, resourceIRN); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound error, got %v", err)
+	}
+
+	if _, err = c.ListResources(ctx, nil, ResourceFilter{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden error, got %v", err)
+	}
+
+	path := "/lab"
+	if err = c.UpdateResource(ctx, nil, resourceIRN, &UpdateResourceRequestDTO{Path: &path}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict error, got %v", err)
+	}
+
+	disabled, err := NewClient("", "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = disabled.SetResourceEnabled(ctx, nil, resourceIRN, true); !errors.Is(err, ErrSDKDisabled) {
+		t.Errorf("expected ErrSDKDisabled error, got %v", err)
+	}
+}
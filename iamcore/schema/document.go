@@ -0,0 +1,91 @@
+// Package schema synchronizes application's resource types on iamcore with a declarative document
+// kept next to the application code.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidDocument = errors.New("invalid schema document")
+
+// Document declares resource types of an application, e.g.
+//
+//	application: fleet
+//	resourceTypes:
+//	  - type: device
+//	    description: Connected device
+//	    actionPrefix: device
+//	    operations: [read, update, delete]
+//
+// JSON documents are accepted as well.
+type Document struct {
+	Application   string          `yaml:"application" json:"application"`
+	ResourceTypes []*ResourceType `yaml:"resourceTypes" json:"resourceTypes"`
+}
+
+// ResourceType declares a resource type and its operations.
+type ResourceType struct {
+	Type         string   `yaml:"type" json:"type"`
+	Description  string   `yaml:"description" json:"description"`
+	ActionPrefix string   `yaml:"actionPrefix" json:"actionPrefix"`
+	Operations   []string `yaml:"operations" json:"operations"`
+}
+
+// Load decodes and validates the document.
+func Load(r io.Reader) (*Document, error) {
+	document := &Document{}
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(document); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidDocument)
+	}
+
+	if err := document.Validate(); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// LoadFile decodes and validates the document file.
+func LoadFile(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return Load(file)
+}
+
+// Validate checks the document has application, and every resource type has type, action prefix and unique name.
+func (d *Document) Validate() error {
+	if d.Application == "" {
+		return fmt.Errorf("application is required: %w", ErrInvalidDocument)
+	}
+
+	types := make(map[string]bool, len(d.ResourceTypes))
+
+	for i, resourceType := range d.ResourceTypes {
+		switch {
+		case resourceType == nil || resourceType.Type == "":
+			return fmt.Errorf("resource type #%d: type is required: %w", i, ErrInvalidDocument)
+		case resourceType.ActionPrefix == "":
+			return fmt.Errorf("resource type %q: action prefix is required: %w", resourceType.Type, ErrInvalidDocument)
+		case types[resourceType.Type]:
+			return fmt.Errorf("resource type %q: duplicated: %w", resourceType.Type, ErrInvalidDocument)
+		}
+
+		types[resourceType.Type] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore"
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/internal/sets"
+)
+
+// ResourceTypeManager is the subset of iamcore.ResourceManager used to synchronize resource types.
+type ResourceTypeManager interface {
+	GetResourceTypes(ctx context.Context, authorizationHeader http.Header, accountID, application string) ([]*iamcore.ResourceTypeResponseDTO, error)
+	CreateResourceTypeWithDescription(ctx context.Context, authorizationHeader http.Header, accountID, application, resourceType, description,
+		actionPrefix string, operations []string) error
+	UpdateResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN,
+		description, actionPrefix string, operations []string) error
+	DeleteResourceType(ctx context.Context, authorizationHeader http.Header, accountID, application string, resourceTypeIRN *irn.IRN) error
+}
+
+var _ ResourceTypeManager = iamcore.Client(nil)
+
+// ChangeKind is the kind of planned resource type change.
+type ChangeKind string
+
+const (
+	ChangeCreate ChangeKind = "create"
+	ChangeUpdate ChangeKind = "update"
+	ChangeDelete ChangeKind = "delete"
+)
+
+// Change is a planned resource type change.
+type Change struct {
+	Kind ChangeKind
+	Type string
+	// Desired resource type; nil for ChangeDelete.
+	Desired *ResourceType
+	// Existing resource type on iamcore; nil for ChangeCreate.
+	Existing *iamcore.ResourceTypeResponseDTO
+	// Details are human-readable descriptions of updated fields.
+	Details []string
+}
+
+// Plan is the list of changes bringing iamcore resource types to the document state.
+type Plan struct {
+	Application string
+	Changes     []*Change
+}
+
+// Empty reports whether iamcore resource types already match the document.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns the human-readable diff of the plan.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return fmt.Sprintf("application %q: no changes\n", p.Application)
+	}
+
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "application %q:\n", p.Application)
+
+	for _, change := range p.Changes {
+		switch change.Kind {
+		case ChangeCreate:
+			fmt.Fprintf(&builder, "+ %s (actionPrefix %q, operations [%s])\n",
+				change.Type, change.Desired.ActionPrefix, strings.Join(change.Desired.Operations, ", "))
+		case ChangeUpdate:
+			fmt.Fprintf(&builder, "~ %s\n", change.Type)
+
+			for _, detail := range change.Details {
+				fmt.Fprintf(&builder, "    %s\n", detail)
+			}
+		case ChangeDelete:
+			fmt.Fprintf(&builder, "- %s\n", change.Type)
+		}
+	}
+
+	return builder.String()
+}
+
+// Options configure synchronization.
+type Options struct {
+	// Prune deletes resource types existing on iamcore but missing in the document.
+	Prune bool
+	// DryRun only plans the changes without applying them.
+	DryRun bool
+}
+
+// Syncer synchronizes application's resource types of the account with the document.
+type Syncer struct {
+	manager   ResourceTypeManager
+	accountID string
+}
+
+func NewSyncer(manager ResourceTypeManager, accountID string) *Syncer {
+	return &Syncer{
+		manager:   manager,
+		accountID: accountID,
+	}
+}
+
+// Plan computes the changes bringing iamcore resource types to the document state.
+func (s *Syncer) Plan(ctx context.Context, authorizationHeader http.Header, document *Document, prune bool) (*Plan, error) {
+	existingTypes, err := s.manager.GetResourceTypes(ctx, authorizationHeader, s.accountID, document.Application)
+	if err != nil {
+		return nil, err
+	}
+
+	existingByType := make(map[string]*iamcore.ResourceTypeResponseDTO, len(existingTypes))
+	for _, existing := range existingTypes {
+		existingByType[existing.Type] = existing
+	}
+
+	plan := &Plan{
+		Application: document.Application,
+		Changes:     make([]*Change, 0),
+	}
+
+	for _, desired := range document.ResourceTypes {
+		existing, ok := existingByType[desired.Type]
+		if !ok {
+			plan.Changes = append(plan.Changes, &Change{Kind: ChangeCreate, Type: desired.Type, Desired: desired})
+
+			continue
+		}
+
+		delete(existingByType, desired.Type)
+
+		if details := diffResourceType(existing, desired); len(details) != 0 {
+			plan.Changes = append(plan.Changes, &Change{Kind: ChangeUpdate, Type: desired.Type, Desired: desired, Existing: existing, Details: details})
+		}
+	}
+
+	if prune {
+		for _, existing := range existingTypes {
+			if _, ok := existingByType[existing.Type]; ok {
+				plan.Changes = append(plan.Changes, &Change{Kind: ChangeDelete, Type: existing.Type, Existing: existing})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// Apply applies the planned changes in order; stops at the first failure.
+func (s *Syncer) Apply(ctx context.Context, authorizationHeader http.Header, plan *Plan) error {
+	for _, change := range plan.Changes {
+		var err error
+
+		switch change.Kind {
+		case ChangeCreate:
+			err = s.manager.CreateResourceTypeWithDescription(ctx, authorizationHeader, s.accountID, plan.Application,
+				change.Type, change.Desired.Description, change.Desired.ActionPrefix, change.Desired.Operations)
+		case ChangeUpdate:
+			err = s.manager.UpdateResourceType(ctx, authorizationHeader, s.accountID, plan.Application,
+				change.Existing.IRN, change.Desired.Description, change.Desired.ActionPrefix, change.Desired.Operations)
+		case ChangeDelete:
+			err = s.manager.DeleteResourceType(ctx, authorizationHeader, s.accountID, plan.Application, change.Existing.IRN)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s resource type %q: %w", change.Kind, change.Type, err)
+		}
+	}
+
+	return nil
+}
+
+// Sync plans and, unless Options.DryRun is set, applies the changes. Returns the plan in any case.
+func (s *Syncer) Sync(ctx context.Context, authorizationHeader http.Header, document *Document, options Options) (*Plan, error) {
+	plan, err := s.Plan(ctx, authorizationHeader, document, options.Prune)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.DryRun {
+		return plan, nil
+	}
+
+	return plan, s.Apply(ctx, authorizationHeader, plan)
+}
+
+func diffResourceType(existing *iamcore.ResourceTypeResponseDTO, desired *ResourceType) []string {
+	details := make([]string, 0)
+
+	if existing.Description != desired.Description {
+		details = append(details, fmt.Sprintf("description: %q -> %q", existing.Description, desired.Description))
+	}
+
+	if existing.ActionPrefix != desired.ActionPrefix {
+		details = append(details, fmt.Sprintf("actionPrefix: %q -> %q", existing.ActionPrefix, desired.ActionPrefix))
+	}
+
+	if diff := sets.Diff(existing.Operations, desired.Operations); diff != "" {
+		details = append(details, "operations: "+diff)
+	}
+
+	return details
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore"
+)
+
+type fakeManager struct {
+	existing []*iamcore.ResourceTypeResponseDTO
+	calls    []string
+}
+
+func (f *fakeManager) GetResourceTypes(context.Context, http.Header, string, string) ([]*iamcore.ResourceTypeResponseDTO, error) {
+	return f.existing, nil
+}
+
+func (f *fakeManager) CreateResourceTypeWithDescription(_ context.Context, _ http.Header, _, _, resourceType, description, _ string, _ []string) error {
+	f.calls = append(f.calls, "create "+resourceType+" "+description)
+
+	return nil
+}
+
+func (f *fakeManager) UpdateResourceType(_ context.Context, _ http.Header, _, _ string, _ *irn.IRN, _, _ string, operations []string) error {
+	f.calls = append(f.calls, "update "+strings.Join(operations, ","))
+
+	return nil
+}
+
+func (f *fakeManager) DeleteResourceType(context.Context, http.Header, string, string, *irn.IRN) error {
+	f.calls = append(f.calls, "delete")
+
+	return nil
+}
+
+const testDocument = `
+application: fleet
+resourceTypes:
+  - type: device
+    description: Connected device
+    actionPrefix: device
+    operations: [read, update, export]
+  - type: gateway
+    description: Edge gateway
+    actionPrefix: gateway
+    operations: [read]
+`
+
+func TestSyncPlanAndApply(t *testing.T) {
+	document, err := Load(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manager := &fakeManager{existing: []*iamcore.ResourceTypeResponseDTO{
+		{Type: "device", Description: "Connected device", ActionPrefix: "device", Operations: []string{"update", "read", "import"}},
+		{Type: "legacy", ActionPrefix: "legacy"},
+	}}
+	syncer := NewSyncer(manager, "rc73dbh7q0")
+
+	plan, err := syncer.Sync(context.Background(), nil, document, Options{Prune: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manager.calls) != 0 {
+		t.Fatalf("expected no changes applied in dry-run mode, got %v", manager.calls)
+	}
+
+	want := "application \"fleet\":\n" +
+		"~ device\n    operations: +export -import\n" +
+		"+ gateway (actionPrefix \"gateway\", operations [read])\n" +
+		"- legacy\n"
+	if plan.String() != want {
+		t.Fatalf("unexpected plan:\n%s\nwant:\n%s", plan, want)
+	}
+
+	if err = syncer.Apply(context.Background(), nil, plan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantCalls := []string{"update read,update,export", "create gateway Edge gateway", "delete"}
+	if strings.Join(manager.calls, ";") != strings.Join(wantCalls, ";") {
+		t.Fatalf("unexpected calls %v, want %v", manager.calls, wantCalls)
+	}
+}
+
+func TestLoadRejectsInvalidDocument(t *testing.T) {
+	if _, err := Load(strings.NewReader("resourceTypes: []")); err == nil {
+		t.Fatal("expected error for document without application")
+	}
+	if _, err := Load(strings.NewReader("application: fleet\nunknown: true")); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
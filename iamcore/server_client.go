@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
@@ -19,6 +21,10 @@ const (
 	applicationPath            = "/api/v1/applications"
 	evaluatePath               = "/api/v1/evaluate"
 	userPath                   = "/api/v1/users"
+	groupPath                  = "/api/v1/groups"
+	apiKeyPath                 = "/api/v1/api-keys"
+	policyPath                 = "/api/v1/policies"
+	tenantPath                 = "/api/v1/tenants"
 	poolPath                   = "/api/v1/pools"
 	evaluateOnResourceTypePath = evaluatePath + "/resources"
 	evaluateActionsOnIRNsPath  = evaluatePath + "/irns/actions"
@@ -237,6 +243,34 @@ func (c *ServerClient) DeleteResource(ctx context.Context, authorizationHeader h
 	return handleServerErrorResponse(response)
 }
 
+func (c *ServerClient) GetResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN) (*ResourceResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s", c.getURL(resourcePath), resourceIRN.Base64())
+	resource := &ResourceResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, &dataResponseDTO{Data: resource}); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (c *ServerClient) GetResources(ctx context.Context, authorizationHeader http.Header, query url.Values) (*ResourcesResponseDTO, error) {
+	requestURL := fmt.Sprintf("%s?%s", c.getURL(resourcePath), query.Encode())
+	responseDTO := &ResourcesResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, requestURL, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO, nil
+}
+
+func (c *ServerClient) UpdateResource(ctx context.Context, authorizationHeader http.Header, resourceIRN *irn.IRN, updateDTO *UpdateResourceRequestDTO) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(resourcePath), resourceIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPatch, url, updateDTO)
+}
+
 func (c *ServerClient) CreateResourceType(ctx context.Context, authorizationHeader http.Header,
 	applicationIRN *irn.IRN, createDTO *CreateResourceTypeRequestDTO,
 ) error {
@@ -268,6 +302,61 @@ func (c *ServerClient) CreateResourceType(ctx context.Context, authorizationHead
 	return handleServerErrorResponse(response)
 }
 
+func (c *ServerClient) UpdateResourceType(ctx context.Context, authorizationHeader http.Header,
+	applicationIRN, resourceTypeIRN *irn.IRN, updateDTO *UpdateResourceTypeRequestDTO,
+) error {
+	url := c.getURL(fmt.Sprintf("%s/%s/resource-types/%s", applicationPath, applicationIRN.Base64(), resourceTypeIRN.Base64()))
+
+	requestDTO, err := json.Marshal(updateDTO)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(requestDTO))
+	if err != nil {
+		return err
+	}
+
+	request.Header = authorizationHeader
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return handleServerErrorResponse(response)
+}
+
+func (c *ServerClient) DeleteResourceType(ctx context.Context, authorizationHeader http.Header, applicationIRN, resourceTypeIRN *irn.IRN) error {
+	url := c.getURL(fmt.Sprintf("%s/%s/resource-types/%s", applicationPath, applicationIRN.Base64(), resourceTypeIRN.Base64()))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header = authorizationHeader
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return handleServerErrorResponse(response)
+}
+
 func (c *ServerClient) GetResourceTypes(ctx context.Context, authorizationHeader http.Header, applicationIRN *irn.IRN) ([]*ResourceTypeResponseDTO, error) {
 	url := c.getURL(fmt.Sprintf("%s/%s/resource-types?pageSize=%d", applicationPath, applicationIRN.Base64(), pageSize))
 
@@ -341,17 +430,31 @@ func (c *ServerClient) EvaluateActionsOnIRNs(ctx context.Context, authorizationH
 
 func (c *ServerClient) EvaluateDebugResources(ctx context.Context, authorizationHeader http.Header,
 	application string, resources []*irn.IRN, actions []string,
+) (*EvaluateDebugResourcesResponseDTO, error) {
+	return c.EvaluateDebugResourcesAsPrincipal(ctx, authorizationHeader, nil, application, resources, actions)
+}
+
+// EvaluateDebugResourcesAsPrincipal evaluates actions on resources against the policies of the principal,
+// rather than of the authenticated one. The authenticated principal must be privileged to evaluate on behalf of others.
+func (c *ServerClient) EvaluateDebugResourcesAsPrincipal(ctx context.Context, authorizationHeader http.Header, principal *irn.IRN,
+	application string, resources []*irn.IRN, actions []string,
 ) (*EvaluateDebugResourcesResponseDTO, error) {
 	base64Resources := make([]*irn.IRN64, len(resources))
 	for i, r := range resources {
 		base64Resources[i] = &irn.IRN64{IRN: *r}
 	}
 
-	payload, err := json.Marshal(&EvaluateDebugResourcesRequestDTO{
+	requestDTO := &EvaluateDebugResourcesRequestDTO{
 		Application: application,
 		Actions:     actions,
 		Resources:   base64Resources,
-	})
+	}
+
+	if principal != nil {
+		requestDTO.Principal = &irn.IRN64{IRN: *principal}
+	}
+
+	payload, err := json.Marshal(requestDTO)
 	if err != nil {
 		return nil, err
 	}
@@ -423,36 +526,224 @@ func (c *ServerClient) AuthorizationDBQueryFilter(ctx context.Context, authoriza
 }
 
 func (c *ServerClient) AttachUserToPolicy(ctx context.Context, authorizationHeader http.Header, userIRN, policyIRN *irn.IRN) error {
-	queryFilterRequestDTO := &AttachPolicyRequestDTO{
-		PolicyIDs: []string{policyIRN.Base64()},
+	return c.AttachPolicies(ctx, authorizationHeader, userPath, userIRN, []*irn.IRN{policyIRN})
+}
+
+func (c *ServerClient) CreatePolicy(ctx context.Context, authorizationHeader http.Header, createDTO *CreatePolicyRequestDTO) (*PolicyResponseDTO, error) {
+	policy := &PolicyResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(policyPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: policy})
+	if err != nil {
+		return nil, err
 	}
 
-	requestDTO, err := json.Marshal(queryFilterRequestDTO)
+	return policy, nil
+}
+
+func (c *ServerClient) GetPolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) (*PolicyResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s", c.getURL(policyPath), policyIRN.Base64())
+	policy := &PolicyResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, &dataResponseDTO{Data: policy}); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (c *ServerClient) GetPolicies(ctx context.Context, authorizationHeader http.Header, query url.Values) (*PoliciesResponseDTO, error) {
+	requestURL := fmt.Sprintf("%s?%s", c.getURL(policyPath), query.Encode())
+	responseDTO := &PoliciesResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, requestURL, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO, nil
+}
+
+func (c *ServerClient) UpdatePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN, updateDTO *UpdatePolicyRequestDTO) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(policyPath), policyIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPatch, url, updateDTO)
+}
+
+func (c *ServerClient) DeletePolicy(ctx context.Context, authorizationHeader http.Header, policyIRN *irn.IRN) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(policyPath), policyIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodDelete, url, nil)
+}
+
+// AttachPolicies attaches policies to principal, where principalPath is the principal collection path, e.g. "/api/v1/groups".
+func (c *ServerClient) AttachPolicies(ctx context.Context, authorizationHeader http.Header, principalPath string, principalIRN *irn.IRN,
+	policyIRNs []*irn.IRN,
+) error {
+	url := c.getURL(fmt.Sprintf("%s/%s/policies/attach", principalPath, principalIRN.Base64()))
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, newAttachPolicyRequestDTO(policyIRNs))
+}
+
+// DetachPolicies detaches policies from principal, where principalPath is the principal collection path, e.g. "/api/v1/groups".
+func (c *ServerClient) DetachPolicies(ctx context.Context, authorizationHeader http.Header, principalPath string, principalIRN *irn.IRN,
+	policyIRNs []*irn.IRN,
+) error {
+	url := c.getURL(fmt.Sprintf("%s/%s/policies/detach", principalPath, principalIRN.Base64()))
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, newAttachPolicyRequestDTO(policyIRNs))
+}
+
+// GetEffectivePolicies retrieves policies in effect for principal, including the ones inherited from groups.
+func (c *ServerClient) GetEffectivePolicies(ctx context.Context, authorizationHeader http.Header, principalPath string, principalIRN *irn.IRN) (
+	[]*PolicyResponseDTO, error,
+) {
+	url := c.getURL(fmt.Sprintf("%s/%s/policies?effective=true&page=1&pageSize=%d", principalPath, principalIRN.Base64(), pageSize))
+	responseDTO := &PoliciesResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO.Data, nil
+}
+
+func (c *ServerClient) CreateUser(ctx context.Context, authorizationHeader http.Header, createDTO *CreateUserRequestDTO) (*UserResponseDTO, error) {
+	user := &UserResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(userPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: user})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *ServerClient) GetUser(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN) (*UserResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s", c.getURL(userPath), userIRN.Base64())
+	user := &UserResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, &dataResponseDTO{Data: user}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *ServerClient) GetUsers(ctx context.Context, authorizationHeader http.Header, query url.Values) (*UsersResponseDTO, error) {
+	requestURL := fmt.Sprintf("%s?%s", c.getURL(userPath), query.Encode())
+	responseDTO := &UsersResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, requestURL, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
 	}
 
-	url := c.getURL(fmt.Sprintf(userPath+"/%s/policies/attach", userIRN.Base64()))
+	return responseDTO, nil
+}
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(requestDTO))
+func (c *ServerClient) UpdateUser(ctx context.Context, authorizationHeader http.Header, userIRN *irn.IRN, updateDTO *UpdateUserRequestDTO) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(userPath), userIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPatch, url, updateDTO)
+}
+
+func (c *ServerClient) CreateGroup(ctx context.Context, authorizationHeader http.Header, createDTO *CreateGroupRequestDTO) (*GroupResponseDTO, error) {
+	group := &GroupResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(groupPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: group})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	request.Header = authorizationHeader
+	return group, nil
+}
 
-	response, err := c.httpClient.Do(request)
+func (c *ServerClient) AddGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error {
+	url := fmt.Sprintf("%s/%s/members/add", c.getURL(groupPath), groupIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, newGroupMembersRequestDTO(userIRNs))
+}
+
+func (c *ServerClient) RemoveGroupMembers(ctx context.Context, authorizationHeader http.Header, groupIRN *irn.IRN, userIRNs []*irn.IRN) error {
+	url := fmt.Sprintf("%s/%s/members/remove", c.getURL(groupPath), groupIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, newGroupMembersRequestDTO(userIRNs))
+}
+
+func (c *ServerClient) CreateAPIKey(ctx context.Context, authorizationHeader http.Header, createDTO *CreateAPIKeyRequestDTO) (*APIKeyResponseDTO, error) {
+	apiKey := &APIKeyResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(apiKeyPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: apiKey})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer response.Body.Close()
+	return apiKey, nil
+}
 
-	if response.StatusCode != http.StatusNoContent {
-		return handleServerErrorResponse(response)
+func (c *ServerClient) GetAPIKeys(ctx context.Context, authorizationHeader http.Header, query url.Values) (*APIKeysResponseDTO, error) {
+	requestURL := fmt.Sprintf("%s?%s", c.getURL(apiKeyPath), query.Encode())
+	responseDTO := &APIKeysResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, requestURL, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO, nil
+}
+
+func (c *ServerClient) DeleteAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(apiKeyPath), apiKeyIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodDelete, url, nil)
+}
+
+func (c *ServerClient) RotateAPIKey(ctx context.Context, authorizationHeader http.Header, apiKeyIRN *irn.IRN) (*APIKeyResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s/rotate", c.getURL(apiKeyPath), apiKeyIRN.Base64())
+	apiKey := &APIKeyResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, url, nil, http.StatusOK, &dataResponseDTO{Data: apiKey}); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return apiKey, nil
+}
+
+func (c *ServerClient) CreateTenant(ctx context.Context, authorizationHeader http.Header, createDTO *CreateTenantRequestDTO) (*TenantResponseDTO, error) {
+	tenant := &TenantResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(tenantPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: tenant})
+	if err != nil {
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (c *ServerClient) GetTenant(ctx context.Context, authorizationHeader http.Header, tenantIRN *irn.IRN) (*TenantResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s", c.getURL(tenantPath), tenantIRN.Base64())
+	tenant := &TenantResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, &dataResponseDTO{Data: tenant}); err != nil {
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (c *ServerClient) GetTenants(ctx context.Context, authorizationHeader http.Header, query url.Values) (*TenantsResponseDTO, error) {
+	requestURL := fmt.Sprintf("%s?%s", c.getURL(tenantPath), query.Encode())
+	responseDTO := &TenantsResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, requestURL, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO, nil
+}
+
+func (c *ServerClient) DeleteTenant(ctx context.Context, authorizationHeader http.Header, tenantIRN *irn.IRN) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(tenantPath), tenantIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodDelete, url, nil)
 }
 
 func (c *ServerClient) GetPools(ctx context.Context, authorizationHeader http.Header, resourceIRN, poolIRN *irn.IRN, poolName string) ([]*PoolResponseDTO, error) {
@@ -506,28 +797,167 @@ func (c *ServerClient) GetPools(ctx context.Context, authorizationHeader http.He
 	return nil, handleServerErrorResponse(response)
 }
 
+func (c *ServerClient) CreatePool(ctx context.Context, authorizationHeader http.Header, createDTO *CreatePoolRequestDTO) (*PoolResponseDTO, error) {
+	pool := &PoolResponseDTO{}
+
+	err := c.sendRequest(ctx, authorizationHeader, http.MethodPost, c.getURL(poolPath), createDTO, http.StatusCreated, &dataResponseDTO{Data: pool})
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func (c *ServerClient) UpdatePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, updateDTO *UpdatePoolRequestDTO) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(poolPath), poolIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPatch, url, updateDTO)
+}
+
+func (c *ServerClient) DeletePool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) error {
+	url := fmt.Sprintf("%s/%s", c.getURL(poolPath), poolIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodDelete, url, nil)
+}
+
+func (c *ServerClient) AttachResourcesToPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error {
+	url := fmt.Sprintf("%s/%s/resources/attach", c.getURL(poolPath), poolIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, &PoolResourcesRequestDTO{ResourceIDs: resourceIDs})
+}
+
+func (c *ServerClient) DetachResourcesFromPool(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN, resourceIDs []string) error {
+	url := fmt.Sprintf("%s/%s/resources/detach", c.getURL(poolPath), poolIRN.Base64())
+
+	return c.sendNoContentRequest(ctx, authorizationHeader, http.MethodPut, url, &PoolResourcesRequestDTO{ResourceIDs: resourceIDs})
+}
+
+func (c *ServerClient) GetPoolResources(ctx context.Context, authorizationHeader http.Header, poolIRN *irn.IRN) ([]*ResourceResponseDTO, error) {
+	url := fmt.Sprintf("%s/%s/resources?page=1&pageSize=%d", c.getURL(poolPath), poolIRN.Base64(), pageSize)
+	responseDTO := &ResourcesResponseDTO{}
+
+	if err := c.sendRequest(ctx, authorizationHeader, http.MethodGet, url, nil, http.StatusOK, responseDTO); err != nil {
+		return nil, err
+	}
+
+	return responseDTO.Data, nil
+}
+
+// listQuery builds query of list request from non-empty parameters and positive page and page size.
+func listQuery(params map[string]string, page, pageSize int) url.Values {
+	query := url.Values{}
+
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+
+	if pageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(pageSize))
+	}
+
+	return query
+}
+
 func (c *ServerClient) getURL(path string) string {
 	return fmt.Sprintf("%s%s", c.serverURL, path)
 }
 
+// APIError is an error response of iamcore server. It wraps one of ErrUnauthenticated, ErrForbidden, ErrConflict,
+// ErrNotFound, ErrBadRequest or ErrUnknown depending on the response status code.
+type APIError struct {
+	// StatusCode of iamcore server response.
+	StatusCode int
+	// Message of iamcore server response.
+	Message string
+
+	err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Message, e.err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// sendNoContentRequest sends the request with optional JSON body, expecting 204 No Content response.
+func (c *ServerClient) sendNoContentRequest(ctx context.Context, authorizationHeader http.Header, method, url string, requestDTO interface{}) error {
+	return c.sendRequest(ctx, authorizationHeader, method, url, requestDTO, http.StatusNoContent, nil)
+}
+
+// sendRequest sends the request with optional JSON body, expecting the response with the status code.
+// The response body is decoded into responseDTO unless it is nil.
+func (c *ServerClient) sendRequest(ctx context.Context, authorizationHeader http.Header, method, url string, requestDTO interface{},
+	expectedStatusCode int, responseDTO interface{},
+) error {
+	var body io.Reader
+
+	if requestDTO != nil {
+		payload, err := json.Marshal(requestDTO)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	request.Header = authorizationHeader
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != expectedStatusCode {
+		return handleServerErrorResponse(response)
+	}
+
+	if responseDTO == nil {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(responseDTO)
+}
+
 func handleServerErrorResponse(response *http.Response) error {
 	responseDTO := &ErrorResponseDTO{}
 	if err := json.NewDecoder(response.Body).Decode(&responseDTO); err != nil {
-		return err
+		responseDTO.Message = http.StatusText(response.StatusCode)
+	}
+
+	apiError := &APIError{
+		StatusCode: response.StatusCode,
+		Message:    responseDTO.Message,
 	}
 
 	switch response.StatusCode {
 	case http.StatusUnauthorized:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrUnauthenticated)
+		apiError.err = ErrUnauthenticated
 	case http.StatusForbidden:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrForbidden)
+		apiError.err = ErrForbidden
 	case http.StatusConflict:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrConflict)
+		apiError.err = ErrConflict
 	case http.StatusNotFound:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrNotFound)
+		apiError.err = ErrNotFound
 	case http.StatusBadRequest:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrBadRequest)
+		apiError.err = ErrBadRequest
 	default:
-		return fmt.Errorf("%s: %w", responseDTO.Message, ErrUnknown)
+		apiError.err = ErrUnknown
 	}
+
+	return apiError
 }
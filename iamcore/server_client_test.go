@@ -0,0 +1,94 @@
+package iamcore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubResponse is the canned response of stubServer.
+type stubResponse struct {
+	status int
+	body   string
+}
+
+// recordedRequest is the request received by stubServer.
+type recordedRequest struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   string
+}
+
+// stubServer is iamcore stub responding with the canned responses keyed by "METHOD /path" and recording the requests.
+type stubServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]stubResponse
+	requests  []*recordedRequest
+}
+
+func newStubServer(t *testing.T, responses map[string]stubResponse) *stubServer {
+	t.Helper()
+
+	s := &stubServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, &recordedRequest{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.Query(),
+			header: r.Header,
+			body:   strings.TrimSpace(string(body)),
+		})
+
+		response, ok := s.responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+
+			response = stubResponse{status: http.StatusNotImplemented}
+		}
+
+		w.WriteHeader(response.status)
+		_, _ = w.Write([]byte(response.body))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// lastRequest returns the last request received by the stub.
+func (s *stubServer) lastRequest(t *testing.T) *recordedRequest {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 {
+		t.Fatal("expected request to iamcore")
+	}
+
+	return s.requests[len(s.requests)-1]
+}
+
+func TestListQuery(t *testing.T) {
+	query := listQuery(map[string]string{"name": "a b", "tenantID": "", "path": "/acme"}, 0, -1).Encode()
+	if query != "name=a+b&path=%2Facme" {
+		t.Fatalf("expected empty parameters and paging omitted, got %s", query)
+	}
+
+	filter := &ResourceFilter{Application: "myapp", ResourceType: "device", PathPrefix: "/lab", Page: 3, PageSize: 25}
+	if query = filter.query().Encode(); query != "application=myapp&page=3&pageSize=25&path=%2Flab&resourceType=device" {
+		t.Fatalf("unexpected resource filter query %s", query)
+	}
+}
@@ -0,0 +1,170 @@
+package iamcore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	eventStreamPath                = "/api/v1/events/stream"
+	defaultStreamReconnectInterval = 5 * time.Second
+)
+
+var errStreamClosed = errors.New("stream closed by server")
+
+// StreamEventSourceOptions configures StreamEventSource.
+type StreamEventSourceOptions struct {
+	// HTTPClient opens the stream; it must not limit the request duration by timeout. http.Client without timeout by default,
+	// see Config.StreamEventSourceOptions for the one of the configured root CAs.
+	HTTPClient *http.Client
+	// ReconnectInterval is the delay before reconnecting the dropped stream; 5 seconds by default.
+	ReconnectInterval time.Duration
+}
+
+// StreamEventSource is ChangeEventSource consuming iamcore change events stream by means of Server-Sent Events.
+// The dropped stream is reconnected, resuming from the last received event.
+//
+// Experimental: the stream endpoint and the event envelope are not part of the published iamcore API yet,
+// so they may change in incompatible ways.
+type StreamEventSource struct {
+	serverURL           string
+	authorizationHeader func(ctx context.Context) (http.Header, error)
+	options             StreamEventSourceOptions
+	lastEventID         string
+}
+
+// NewStreamEventSource creates StreamEventSource authenticated on iamcore with the authorization header, e.g.
+// client.ServiceAuthorizationHeader. The header is resolved on each (re)connect, so rotated credentials are picked up.
+func NewStreamEventSource(
+	serverURL string, authorizationHeader func(ctx context.Context) (http.Header, error), options StreamEventSourceOptions,
+) *StreamEventSource {
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{}
+	}
+
+	if options.ReconnectInterval == 0 {
+		options.ReconnectInterval = defaultStreamReconnectInterval
+	}
+
+	return &StreamEventSource{
+		serverURL:           serverURL,
+		authorizationHeader: authorizationHeader,
+		options:             options,
+	}
+}
+
+// Run consumes the stream until the context is done. Transient stream failures are logged and the stream is reconnected;
+// authentication and authorization failures are returned.
+func (s *StreamEventSource) Run(ctx context.Context, handle func(event *ChangeEvent)) error {
+	for {
+		err := s.consume(ctx, handle)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if isUnauthorizedError(err) {
+			return err
+		}
+
+		log.Printf("iamcore change events stream interrupted, reconnecting: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.options.ReconnectInterval):
+		}
+	}
+}
+
+func (s *StreamEventSource) consume(ctx context.Context, handle func(event *ChangeEvent)) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.serverURL+eventStreamPath, nil)
+	if err != nil {
+		return err
+	}
+
+	if s.authorizationHeader != nil {
+		header, err := s.authorizationHeader(ctx)
+		if err != nil {
+			return fmt.Errorf("resolve change events stream credentials: %w", err)
+		}
+
+		request.Header = header.Clone()
+	}
+
+	if request.Header == nil {
+		request.Header = http.Header{}
+	}
+
+	request.Header.Set("Accept", "text/event-stream")
+
+	if s.lastEventID != "" {
+		request.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+
+	response, err := s.options.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return handleServerErrorResponse(response)
+	}
+
+	var eventID string
+
+	data := make([]string, 0)
+	scanner := bufio.NewScanner(response.Body)
+	// The event is limited in size the same as the webhook delivery, which exceeds the default scanner line limit.
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxWebhookBodySize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if len(data) != 0 {
+				s.dispatch(eventID, strings.Join(data, "\n"), handle)
+			}
+
+			eventID, data = "", data[:0]
+		case strings.HasPrefix(line, "id:"):
+			eventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	return errStreamClosed
+}
+
+// dispatch handles the event and records its ID to resume from. Malformed event is skipped, as otherwise
+// the stream resumed from the preceding event would deliver it again.
+func (s *StreamEventSource) dispatch(eventID, data string, handle func(event *ChangeEvent)) {
+	event, err := decodeChangeEvent([]byte(data))
+	if err != nil {
+		log.Printf("Skipping malformed iamcore change event %q: %v", eventID, err)
+	} else {
+		handle(event)
+	}
+
+	if eventID != "" {
+		s.lastEventID = eventID
+	}
+}
+
+func isUnauthorizedError(err error) bool {
+	return err != nil && (errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrForbidden))
+}
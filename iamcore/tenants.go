@@ -0,0 +1,160 @@
+package iamcore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/irn.git"
+)
+
+// TenantFilter filters tenants listed by ListTenants. Empty fields are not applied.
+type TenantFilter struct {
+	Name string
+	// PathPrefix matches tenants having the path or nested paths.
+	PathPrefix string
+	// Page number, starting from 1; the first page by default.
+	Page int
+	// PageSize is the maximum number of tenants in the page; iamcore default if zero.
+	PageSize int
+}
+
+// Tenants manages iamcore tenants.
+type Tenants interface {
+	// CreateTenant creates tenant on iamcore. The tenant is nested into the parent tenants listed in tenantPath,
+	// e.g. "/acme/europe"; top-level tenant is created if tenantPath is empty.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrConflict error in case duplicated tenant found.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to create the tenant.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	CreateTenant(ctx context.Context, authorizationHeader http.Header, tenantPath, name, displayName string) (*TenantResponseDTO, error)
+
+	// GetTenant retrieves tenant from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case tenant does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the tenant.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	GetTenant(ctx context.Context, authorizationHeader http.Header, accountID, tenantID string) (*TenantResponseDTO, error)
+
+	// ListTenants retrieves a page of tenants matching the filter from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read tenants.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	ListTenants(ctx context.Context, authorizationHeader http.Header, filter TenantFilter) (*TenantsResponseDTO, error)
+
+	// DeleteTenant deletes tenant on iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case tenant does not exist.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to delete the tenant.
+	// Returns ErrBadRequest error in case of invalid request.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	DeleteTenant(ctx context.Context, authorizationHeader http.Header, accountID, tenantID string) error
+
+	// GetPrincipalTenant retrieves tenant of the authenticated principal from iamcore.
+	//
+	// Returns ErrSDKDisabled error in case SDK is disabled.
+	// Returns ErrUnauthenticated error in case of unauthenticated access.
+	// Returns ErrNotFound error in case the principal does not belong to any tenant.
+	// Returns ErrForbidden error in case authenticated principal does not have sufficient permissions to read the tenant.
+	// Returns ErrUnknown error in case of unexpected response from iamcore server.
+	GetPrincipalTenant(ctx context.Context, authorizationHeader http.Header) (*TenantResponseDTO, error)
+}
+
+func (c *client) CreateTenant(ctx context.Context, authorizationHeader http.Header, tenantPath, name, displayName string) (*TenantResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	createTenantRequestDTO := &CreateTenantRequestDTO{
+		Name:        name,
+		DisplayName: displayName,
+		Path:        normalizeTenantPath(tenantPath),
+	}
+
+	return c.iamcoreClient.CreateTenant(ctx, authorizationHeader, createTenantRequestDTO)
+}
+
+func (c *client) GetTenant(ctx context.Context, authorizationHeader http.Header, accountID, tenantID string) (*TenantResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	tenantIRN, err := buildTenantIRN(accountID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.iamcoreClient.GetTenant(ctx, authorizationHeader, tenantIRN)
+}
+
+func (c *client) ListTenants(ctx context.Context, authorizationHeader http.Header, filter TenantFilter) (*TenantsResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	return c.iamcoreClient.GetTenants(ctx, authorizationHeader, filter.query())
+}
+
+func (c *client) DeleteTenant(ctx context.Context, authorizationHeader http.Header, accountID, tenantID string) error {
+	if c.disabled {
+		return ErrSDKDisabled
+	}
+
+	tenantIRN, err := buildTenantIRN(accountID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.iamcoreClient.DeleteTenant(ctx, authorizationHeader, tenantIRN)
+}
+
+func (c *client) GetPrincipalTenant(ctx context.Context, authorizationHeader http.Header) (*TenantResponseDTO, error) {
+	if c.disabled {
+		return nil, ErrSDKDisabled
+	}
+
+	principalIRN, err := c.iamcoreClient.GetPrincipalIRN(ctx, authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if principalIRN.GetTenantID() == "" {
+		return nil, fmt.Errorf("principal %s does not belong to any tenant: %w", principalIRN, ErrNotFound)
+	}
+
+	return c.GetTenant(ctx, authorizationHeader, principalIRN.GetAccountID(), principalIRN.GetTenantID())
+}
+
+func (f *TenantFilter) query() url.Values {
+	pathPrefix := ""
+	if f.PathPrefix != "" {
+		pathPrefix = normalizeTenantPath(f.PathPrefix)
+	}
+
+	return listQuery(map[string]string{
+		"name": f.Name,
+		"path": pathPrefix,
+	}, f.Page, f.PageSize)
+}
+
+func buildTenantIRN(accountID, tenantID string) (*irn.IRN, error) {
+	return irn.NewIRN(accountID, "iamcore", "", nil, "tenant", nil, tenantID)
+}
+
+// normalizeTenantPath returns tenant path in the form used by IRN paths, e.g. "/acme/europe"; "/" for top-level tenants.
+func normalizeTenantPath(tenantPath string) string {
+	return "/" + strings.Join(irn.SplitPath(tenantPath), "/")
+}
@@ -0,0 +1,90 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTenants(t *testing.T) {
+	tenantIRN, err := buildTenantIRN("rc73dbh7q0", "europe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tenantURLPath := tenantPath + "/" + tenantIRN.Base64()
+
+	server := newStubServer(t, map[string]stubResponse{
+		"POST " + tenantPath:      {http.StatusCreated, `{"data":{"id":"europe","tenantID":"europe","name":"europe","path":"/acme"}}`},
+		"GET " + tenantPath:       {http.StatusOK, `{"data":[{"tenantID":"europe"}],"count":1}`},
+		"GET " + tenantURLPath:    {http.StatusOK, `{"data":{"tenantID":"europe","displayName":"Europe"}}`},
+		"DELETE " + tenantURLPath: {http.StatusConflict, `{"message":"tenant has nested tenants"}`},
+		"GET " + userIRNPath:      {http.StatusOK, `{"data":"irn:rc73dbh7q0:iamcore:europe::user/john"}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+	ctx := context.Background()
+
+	tenant, err := c.CreateTenant(ctx, nil, "acme/", "europe", "")
+	if err != nil || tenant.TenantID != "europe" {
+		t.Fatalf("unexpected tenant %+v: %v", tenant, err)
+	}
+
+	if body := server.lastRequest(t).body; body != `{"name":"europe","path":"/acme"}` {
+		t.Fatalf("unexpected create request %s", body)
+	}
+
+	if tenant, err = c.GetTenant(ctx, nil, "rc73dbh7q0", "europe"); err != nil || tenant.DisplayName != "Europe" {
+		t.Fatalf("unexpected tenant %+v: %v", tenant, err)
+	}
+
+	tenants, err := c.ListTenants(ctx, nil, TenantFilter{PathPrefix: "acme", Page: 1, PageSize: 5})
+	if err != nil || tenants.Count != 1 {
+		t.Fatalf("unexpected tenants %+v: %v", tenants, err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "page=1&pageSize=5&path=%2Facme" {
+		t.Fatalf("unexpected list query %s", query)
+	}
+
+	if tenant, err = c.GetPrincipalTenant(ctx, nil); err != nil || tenant.TenantID != "europe" {
+		t.Fatalf("unexpected principal tenant %+v: %v", tenant, err)
+	}
+
+	if path := server.lastRequest(t).path; path != tenantURLPath {
+		t.Fatalf("expected principal tenant requested, got %s", path)
+	}
+
+	if err = c.DeleteTenant(ctx, nil, "rc73dbh7q0", "europe"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict error, got %v", err)
+	}
+}
+
+func TestGetPrincipalTenantWithoutTenant(t *testing.T) {
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + userIRNPath: {http.StatusOK, `{"data":"irn:rc73dbh7q0:iamcore:::user/john"}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+
+	if _, err := c.GetPrincipalTenant(context.Background(), nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound error, got %v", err)
+	}
+}
+
+func TestListTenantsQuery(t *testing.T) {
+	server := newStubServer(t, map[string]stubResponse{
+		"GET " + tenantPath: {http.StatusOK, `{"data":[]}`},
+	})
+
+	c := newServerTestClient(t, server.Server)
+
+	if _, err := c.ListTenants(context.Background(), nil, TenantFilter{Name: "europe"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query := server.lastRequest(t).query.Encode(); query != "name=europe" {
+		t.Fatalf("expected empty path prefix and paging omitted, got %s", query)
+	}
+}
@@ -0,0 +1,213 @@
+package iamcore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenType        = "urn:ietf:params:oauth:token-type:access_token"
+
+	defaultTokenExpiryLeeway = 30 * time.Second
+)
+
+// TokenExchangeOptions configures TokenExchanger.
+type TokenExchangeOptions struct {
+	// ClientID and ClientSecret authenticate the exchanging service on the token endpoint, if set.
+	ClientID     string
+	ClientSecret string
+	// Scopes down-scope the exchanged tokens; the subject token scopes are kept if empty.
+	Scopes []string
+	// HTTPClient calls the token endpoint; the default SDK HTTP client if not set, see Config.TokenExchangeOptions for the configured one.
+	HTTPClient *http.Client
+	// ExpiryLeeway is the time before expiry when the cached token is exchanged again; 30 seconds by default.
+	ExpiryLeeway time.Duration
+}
+
+// ExchangedToken is the audience restricted token issued in exchange for the subject token.
+type ExchangedToken struct {
+	AccessToken     string
+	TokenType       string
+	IssuedTokenType string
+	Scope           string
+	// ExpiresAt is zero in case the token lifetime is not known.
+	ExpiresAt time.Time
+}
+
+// AuthorizationHeader returns "Authorization" header carrying the token.
+func (t *ExchangedToken) AuthorizationHeader() http.Header {
+	return http.Header{authorizationHeaderName: {"Bearer " + t.AccessToken}}
+}
+
+// TokenExchanger exchanges user tokens for down-scoped, audience restricted tokens by means of
+// OAuth 2.0 Token Exchange (RFC 8693), e.g. to call another service on behalf of the user.
+// The exchanged tokens are cached per subject token and audience until near expiry.
+type TokenExchanger struct {
+	tokenURL string
+	options  TokenExchangeOptions
+
+	mu     sync.Mutex
+	tokens map[string]*ExchangedToken
+}
+
+// NewTokenExchanger creates TokenExchanger using the token endpoint, e.g. iamcore server URL followed by OAuth2TokenPath.
+func NewTokenExchanger(tokenURL string, options TokenExchangeOptions) *TokenExchanger {
+	if options.HTTPClient == nil {
+		options.HTTPClient = newDefaultHTTPClient()
+	}
+
+	if options.ExpiryLeeway == 0 {
+		options.ExpiryLeeway = defaultTokenExpiryLeeway
+	}
+
+	return &TokenExchanger{
+		tokenURL: tokenURL,
+		options:  options,
+		tokens:   make(map[string]*ExchangedToken),
+	}
+}
+
+// Exchange returns the token for the audience issued in exchange for the subject access token.
+//
+// Returns ErrUnauthenticated error in case the subject token is invalid or expired.
+// Returns ErrForbidden error in case the exchange for the audience or scopes is not allowed.
+// Returns ErrBadRequest error in case of invalid request.
+func (e *TokenExchanger) Exchange(ctx context.Context, subjectToken, audience string) (*ExchangedToken, error) {
+	key := tokenCacheKey(subjectToken, audience)
+	now := time.Now()
+
+	e.mu.Lock()
+	token, ok := e.tokens[key]
+	e.mu.Unlock()
+
+	if ok && e.valid(token, now) {
+		return token, nil
+	}
+
+	form := url.Values{
+		"grant_type":           {tokenExchangeGrantType},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {accessTokenType},
+		"requested_token_type": {accessTokenType},
+		"audience":             {audience},
+	}
+
+	if len(e.options.Scopes) != 0 {
+		form.Set("scope", strings.Join(e.options.Scopes, " "))
+	}
+
+	response, err := requestOAuth2Token(ctx, e.options.HTTPClient, e.tokenURL, form, e.options.ClientID, e.options.ClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("exchange token for audience %q: %w", audience, err)
+	}
+
+	token = &ExchangedToken{
+		AccessToken:     response.AccessToken,
+		TokenType:       response.TokenType,
+		IssuedTokenType: response.IssuedTokenType,
+		Scope:           response.Scope,
+		ExpiresAt:       response.expiresAt(now),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for cachedKey, cached := range e.tokens {
+		if !e.valid(cached, now) {
+			delete(e.tokens, cachedKey)
+		}
+	}
+
+	// Tokens of unknown lifetime are not cached, as they cannot be told expired.
+	if !token.ExpiresAt.IsZero() {
+		e.tokens[key] = token
+	}
+
+	return token, nil
+}
+
+// Transport returns http.RoundTripper calling the audience service on behalf of the principal authenticated by WithAuth.
+// The outbound request context must be derived from the incoming request context; the principal bearer token is exchanged
+// for the audience token, which replaces "Authorization" header of the request. Principals authenticated other than
+// by bearer token cannot be propagated, so the requests fail with ErrNoAuthContext error.
+func (e *TokenExchanger) Transport(base http.RoundTripper, audience string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &tokenExchangeRoundTripper{base: base, exchanger: e, audience: audience}
+}
+
+func (e *TokenExchanger) valid(token *ExchangedToken, now time.Time) bool {
+	return now.Add(e.options.ExpiryLeeway).Before(token.ExpiresAt)
+}
+
+type tokenExchangeRoundTripper struct {
+	base      http.RoundTripper
+	exchanger *TokenExchanger
+	audience  string
+}
+
+func (rt *tokenExchangeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	outbound, err := rt.authorize(req)
+	if err != nil {
+		// RoundTripper must always close the request body, even on errors.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	return rt.base.RoundTrip(outbound)
+}
+
+// authorize returns the clone of the request authorized by the audience token exchanged for the principal bearer token.
+func (rt *tokenExchangeRoundTripper) authorize(req *http.Request) (*http.Request, error) {
+	authorizationHeader, ok := req.Context().Value(principalAuthorizationHeaderKey).(http.Header)
+	if !ok {
+		return nil, ErrNoAuthContext
+	}
+
+	subjectToken, ok := bearerToken(authorizationHeader)
+	if !ok {
+		return nil, fmt.Errorf("principal is not authenticated by bearer token: %w", ErrNoAuthContext)
+	}
+
+	token, err := rt.exchanger.Exchange(req.Context(), subjectToken, rt.audience)
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTripper must not modify the original request.
+	outbound := req.Clone(req.Context())
+	outbound.Header.Set(authorizationHeaderName, "Bearer "+token.AccessToken)
+
+	return outbound, nil
+}
+
+func bearerToken(authorizationHeader http.Header) (string, bool) {
+	const prefix = "Bearer "
+
+	value := authorizationHeader.Get(authorizationHeaderName)
+	if len(value) <= len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return value[len(prefix):], true
+}
+
+// tokenCacheKey identifies the subject token and audience without keeping the subject token in memory.
+func tokenCacheKey(subjectToken, audience string) string {
+	sum := sha256.Sum256([]byte(subjectToken))
+
+	return hex.EncodeToString(sum[:]) + " " + audience
+}
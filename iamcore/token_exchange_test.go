@@ -0,0 +1,107 @@
+package iamcore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTokenExchangeTransport(t *testing.T) {
+	var exchanges int
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+
+		if r.FormValue("grant_type") != tokenExchangeGrantType || r.FormValue("audience") != "service-b" {
+			t.Errorf("unexpected exchange request %v", r.Form)
+		}
+
+		if r.FormValue("subject_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"subject token is revoked"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token":"exchanged-` + r.FormValue("subject_token") + `","token_type":"Bearer","expires_in":300}`))
+	}))
+	defer tokenServer.Close()
+
+	var received []string
+
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(authorizationHeaderName))
+	}))
+	defer serviceB.Close()
+
+	exchanger := NewTokenExchanger(tokenServer.URL+OAuth2TokenPath, TokenExchangeOptions{HTTPClient: tokenServer.Client()})
+	httpClient := &http.Client{Transport: exchanger.Transport(nil, "service-b")}
+
+	ctx := withPrincipal(context.Background(), &Principal{}, http.Header{authorizationHeaderName: {"Bearer user-token"}})
+
+	for i := 0; i < 2; i++ {
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, serviceB.URL, nil)
+
+		response, err := httpClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		response.Body.Close()
+	}
+
+	if exchanges != 1 || len(received) != 2 || received[1] != "Bearer exchanged-user-token" {
+		t.Fatalf("expected single exchange propagated twice, got %d exchanges, received %v", exchanges, received)
+	}
+
+	_, err := exchanger.Exchange(context.Background(), "revoked", "service-b")
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	body := &closeRecordingBody{Reader: strings.NewReader("payload")}
+
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, serviceB.URL, body)
+	if _, err = httpClient.Do(request); !errors.Is(err, ErrNoAuthContext) {
+		t.Fatalf("expected ErrNoAuthContext without principal, got %v", err)
+	}
+
+	if !body.closed {
+		t.Fatalf("expected request body closed")
+	}
+}
+
+type closeRecordingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeRecordingBody) Close() error {
+	b.closed = true
+
+	return nil
+}
+
+func TestOAuth2ErrorPlainTextResponse(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	exchanger := NewTokenExchanger(tokenServer.URL+OAuth2TokenPath, TokenExchangeOptions{HTTPClient: tokenServer.Client()})
+
+	_, err := exchanger.Exchange(context.Background(), "user-token", "service-b")
+
+	var oauth2Error *OAuth2Error
+	if !errors.Is(err, ErrUnauthenticated) || !errors.As(err, &oauth2Error) || oauth2Error.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected ErrUnauthenticated OAuth2 error, got %v", err)
+	}
+
+	if err = (&OAuth2Error{StatusCode: http.StatusForbidden, Code: "invalid_request"}).Unwrap(); err != ErrForbidden {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package iamcore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"gitlab.kaaiot.net/core/lib/iamcore/iamcore-sdk-go.git/iamcore/events"
+)
+
+const (
+	// WebhookSignatureHeaderName carries the webhook delivery signature; it is events.SignatureHeaderName.
+	WebhookSignatureHeaderName = events.SignatureHeaderName
+
+	maxWebhookBodySize = 1 << 20
+)
+
+// ErrInvalidSignature is events.ErrInvalidSignature.
+var ErrInvalidSignature = events.ErrInvalidSignature
+
+// WebhookEventSource is ChangeEventSource receiving change events pushed by iamcore webhook.
+// It is http.Handler to be mounted at the webhook URL; the requests are accepted only while Run is in progress.
+// The deliveries are iamcore events signed the same way as the ones received by events.Handler.
+//
+// Experimental: the webhook delivery format is the one of events package, see there.
+type WebhookEventSource struct {
+	verifier *events.Verifier
+
+	mu     sync.RWMutex
+	handle func(event *ChangeEvent)
+}
+
+// NewWebhookEventSource creates WebhookEventSource verifying the request signatures with the webhook secret.
+func NewWebhookEventSource(secret string) *WebhookEventSource {
+	return &WebhookEventSource{verifier: events.NewVerifier(secret)}
+}
+
+func (s *WebhookEventSource) Run(ctx context.Context, handle func(event *ChangeEvent)) error {
+	s.mu.Lock()
+	s.handle = handle
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	s.handle = nil
+	s.mu.Unlock()
+
+	return ctx.Err()
+}
+
+func (s *WebhookEventSource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponseMessage(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+
+		return
+	}
+
+	// The byte over the limit tells the oversized body from the one of exactly the limit size.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize+1))
+	if err != nil {
+		writeResponseMessage(w, http.StatusBadRequest, "failed to read request body")
+
+		return
+	}
+
+	if len(body) > maxWebhookBodySize {
+		writeResponseMessage(w, http.StatusRequestEntityTooLarge, "request body is too large")
+
+		return
+	}
+
+	if err = s.verifier.Verify(r.Header, body); err != nil {
+		writeResponseMessage(w, http.StatusUnauthorized, err.Error())
+
+		return
+	}
+
+	event, err := decodeChangeEvent(body)
+	if err != nil {
+		writeResponseMessage(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.mu.RLock()
+	handle := s.handle
+	s.mu.RUnlock()
+
+	// iamcore retries the delivery later in case the subscriber is not running yet.
+	if handle == nil {
+		writeResponseMessage(w, http.StatusServiceUnavailable, "change events subscriber is not running")
+
+		return
+	}
+
+	handle(event)
+
+	w.WriteHeader(http.StatusNoContent)
+}